@@ -0,0 +1,176 @@
+// Package bakery exposes Bakery's manifest filtering so other Go services can
+// filter HLS, DASH and WebVTT manifests in-process. Unlike the server, it does not
+// read any environment configuration nor requires Propeller credentials.
+package bakery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cbsinteractive/bakery/config"
+	"github.com/cbsinteractive/bakery/filters"
+	"github.com/cbsinteractive/bakery/parsers"
+)
+
+// MediaFilters holds the filters applied to a manifest
+type MediaFilters = parsers.MediaFilters
+
+// Protocol describes the protocol of a manifest
+type Protocol = parsers.Protocol
+
+// Decision describes whether a rendition was kept and which filter removed it
+type Decision = filters.Decision
+
+// Issue describes a structural problem found in a filtered manifest
+type Issue = filters.Issue
+
+// HLSPlugin modifies HLS playlists once they have been filtered
+type HLSPlugin = filters.HLSPlugin
+
+// DASHPlugin modifies a DASH manifest once it has been filtered
+type DASHPlugin = filters.DASHPlugin
+
+// SessionData is an EXT-X-SESSION-DATA entry added to HLS master playlists
+type SessionData = parsers.SessionData
+
+// RewriteRule rewrites the role of the renditions signaling a descriptor
+type RewriteRule = filters.RewriteRule
+
+// Supported protocols
+const (
+	ProtocolHLS  = parsers.ProtocolHLS
+	ProtocolDASH = parsers.ProtocolDASH
+	ProtocolVTT  = parsers.ProtocolVTT
+)
+
+const defaultTimeout = 5 * time.Second
+
+// HTTPClient is the client used by filters that fetch child playlists
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Options configures how a manifest is filtered. The zero value is valid
+type Options struct {
+	// HTTPClient is used by filters fetching child playlists, such as DeWeave
+	// or session key hoisting. Defaults to http.DefaultClient
+	HTTPClient HTTPClient
+	// Timeout bounds each request made with HTTPClient. Defaults to 5s
+	Timeout time.Duration
+	// Hostname is the Bakery host referenced by trimmed variant URLs.
+	// Defaults to localhost
+	Hostname string
+}
+
+// Result holds a filtered manifest along with the response metadata the
+// server would have set
+type Result struct {
+	Manifest    string
+	ContentType string
+	// MaxAge is the Cache-Control max-age suggested for the manifest, empty if none
+	MaxAge string
+	// Decisions holds why each rendition was kept or removed
+	Decisions []Decision
+	// Issues holds the structural problems of the filtered manifest, such as
+	// a master playlist left without variants
+	Issues []Issue
+}
+
+// ParseFilters parses a Bakery filter path, such as /v(hevc)/b(0,3000000)/,
+// into MediaFilters for the given protocol
+func ParseFilters(filterPath string, protocol Protocol) (*MediaFilters, error) {
+	return parsers.FilterParse(filterPath, protocol)
+}
+
+// RegisterHLSPlugin makes an HLS plugin available under the given name, which
+// is selected with the [name] filter path segment
+func RegisterHLSPlugin(name string, plugin HLSPlugin) error {
+	return filters.RegisterHLSPlugin(name, plugin)
+}
+
+// RegisterDASHPlugin makes a DASH plugin available under the given name, which
+// is selected with the [name] filter path segment
+func RegisterDASHPlugin(name string, plugin DASHPlugin) error {
+	return filters.RegisterDASHPlugin(name, plugin)
+}
+
+// RegisterRewriteRules makes the rewrite rules available as both an HLS and a
+// DASH plugin under the given name, selected with the [name] filter path segment
+func RegisterRewriteRules(name string, rules []RewriteRule) error {
+	return filters.RegisterRewriteRules(name, rules)
+}
+
+// FilterManifest applies the filters to the manifest body. The base URL is the
+// absolute URL the manifest was fetched from and is used to resolve relative URIs.
+// The filters are left untouched so that they can be shared across calls
+func FilterManifest(ctx context.Context, protocol Protocol, baseURL, body string, original *MediaFilters, opts Options) (*Result, error) {
+	mf := &MediaFilters{}
+	if original != nil {
+		*mf = *original
+	}
+	mf.Protocol = protocol
+
+	c := opts.config()
+
+	var f filters.Filter
+	var contentType string
+	switch protocol {
+	case ProtocolHLS:
+		f, contentType = filters.NewHLSFilter(baseURL, body, c), "application/x-mpegURL"
+	case ProtocolDASH:
+		f, contentType = filters.NewDASHFilter(baseURL, body, c), "application/dash+xml"
+	case ProtocolVTT:
+		f, contentType = filters.NewVTTFilter(baseURL, body, c), "text/vtt"
+	default:
+		return nil, fmt.Errorf("unsupported protocol %q", protocol)
+	}
+
+	manifest, err := f.FilterContent(ctx, mf)
+	if err != nil {
+		return nil, fmt.Errorf("filtering manifest: %w", err)
+	}
+
+	result := &Result{
+		Manifest:    manifest,
+		ContentType: contentType,
+	}
+
+	if maxAge := f.GetMaxAge(); maxAge != "" && maxAge != "0" {
+		result.MaxAge = maxAge
+	}
+
+	if e, ok := f.(filters.Explainer); ok {
+		result.Decisions = e.Decisions()
+	}
+	result.Issues = filters.ValidateManifest(mf, manifest)
+
+	return result, nil
+}
+
+// config builds the filter configuration from the options
+func (o Options) config() config.Config {
+	c := config.Config{
+		Hostname: o.Hostname,
+		Client: config.Client{
+			Timeout:    o.Timeout,
+			HTTPClient: o.HTTPClient,
+		},
+	}
+
+	if c.Hostname == "" {
+		c.Hostname = "localhost"
+		c.Listen = ":8080"
+	}
+
+	if c.Client.Timeout == 0 {
+		c.Client.Timeout = defaultTimeout
+	}
+
+	if c.Client.HTTPClient == nil {
+		c.Client.HTTPClient = http.DefaultClient
+	}
+
+	return c
+}
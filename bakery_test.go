@@ -0,0 +1,160 @@
+package bakery
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	test "github.com/cbsinteractive/bakery/tests"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBakery_FilterManifest(t *testing.T) {
+	masterManifest := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a"
+link_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a"
+link_1_backup.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=8000,CODECS="avc1.77.30,mp4a"
+link_2.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=8000,CODECS="avc1.77.30,mp4a"
+link_2_backup.m3u8
+`
+
+	variantManifest := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:6
+#EXTINF:6.000,
+segment_1.ts
+`
+
+	var fetched []string
+	client := test.MockClient(func(req *http.Request) (*http.Response, error) {
+		fetched = append(fetched, req.URL.String())
+		resp := &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(variantManifest)),
+			Header:     http.Header{},
+		}
+		resp.Header.Add("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+
+		return resp, nil
+	})
+
+	tests := []struct {
+		name              string
+		protocol          Protocol
+		filterPath        string
+		opts              Options
+		expectManifest    string
+		expectContentType string
+		expectFetched     []string
+		expectIssues      []Issue
+		expectErr         bool
+	}{
+		{
+			name:       "when filters are applied, expect the filtered manifest with relative uris resolved",
+			protocol:   ProtocolHLS,
+			filterPath: "/b(0,5000)/",
+			expectManifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a"
+http://existing.base/uri/link_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a"
+http://existing.base/uri/link_1_backup.m3u8
+`,
+			expectContentType: "application/x-mpegURL",
+		},
+		{
+			name:       "when a filter fetches child playlists, expect the provided http client to be used",
+			protocol:   ProtocolHLS,
+			filterPath: "/dw(true)/",
+			opts:       Options{HTTPClient: client},
+			expectManifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a"
+http://existing.base/uri/link_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=8000,CODECS="avc1.77.30,mp4a"
+http://existing.base/uri/link_2.m3u8
+`,
+			expectContentType: "application/x-mpegURL",
+			expectFetched:     []string{"http://existing.base/uri/link_1.m3u8"},
+		},
+		{
+			name:              "when filters remove every variant, expect the issue reported",
+			protocol:          ProtocolHLS,
+			filterPath:        "/b(0,1000)/",
+			expectManifest:    "#EXTM3U\n#EXT-X-VERSION:3\n",
+			expectContentType: "application/x-mpegURL",
+			expectIssues:      []Issue{{Severity: "error", Message: "master playlist has no variants"}},
+		},
+		{
+			name:      "when protocol is not supported, expect an error",
+			protocol:  Protocol("smooth"),
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fetched = nil
+
+			mf, err := ParseFilters(tc.filterPath, tc.protocol)
+			if err != nil {
+				t.Fatalf("ParseFilters() didnt expect an error to be returned, got: %v", err)
+			}
+
+			got, err := FilterManifest(context.Background(), tc.protocol, "http://existing.base/uri/master.m3u8", masterManifest, mf, tc.opts)
+			if err != nil && !tc.expectErr {
+				t.Fatalf("FilterManifest() didnt expect an error to be returned, got: %v", err)
+			} else if err == nil && tc.expectErr {
+				t.Fatal("FilterManifest() expected an error, got nil")
+			} else if tc.expectErr {
+				return
+			}
+
+			if !cmp.Equal(got.Manifest, tc.expectManifest) {
+				t.Errorf("Wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v",
+					got.Manifest, tc.expectManifest, cmp.Diff(got.Manifest, tc.expectManifest))
+			}
+
+			if got.ContentType != tc.expectContentType {
+				t.Errorf("Wrong content type returned\ngot %v\nexpected: %v", got.ContentType, tc.expectContentType)
+			}
+
+			if !cmp.Equal(fetched, tc.expectFetched) {
+				t.Errorf("Wrong urls fetched\ngot %v\nexpected: %v", fetched, tc.expectFetched)
+			}
+
+			if !cmp.Equal(got.Issues, tc.expectIssues) {
+				t.Errorf("Wrong issues returned\ngot %v\nexpected: %v", got.Issues, tc.expectIssues)
+			}
+		})
+	}
+}
+
+func TestBakery_FilterManifest_KeepsFilters(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a"
+link_1.m3u8
+`
+
+	mf, err := ParseFilters("/b(0,5000)/", ProtocolDASH)
+	if err != nil {
+		t.Fatalf("ParseFilters() didnt expect an error to be returned, got: %v", err)
+	}
+	expect := *mf
+
+	if _, err := FilterManifest(context.Background(), ProtocolHLS, "http://existing.base/uri/master.m3u8", manifest, mf, Options{}); err != nil {
+		t.Fatalf("FilterManifest() didnt expect an error to be returned, got: %v", err)
+	}
+
+	if !cmp.Equal(*mf, expect) {
+		t.Errorf("FilterManifest() modified the filters\ngot %v\nexpected: %v\ndiff: %v", *mf, expect, cmp.Diff(*mf, expect))
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+// maxDiffEdits bounds the edit distance searched for. Beyond it the
+// manifests are reported as entirely removed and added. Only the frontier
+// reached at each step is recorded, so memory grows with the square of the
+// edit distance and stays bounded for large media playlists where every URI
+// was rewritten
+const maxDiffEdits = 1000
+
+// lineDiff returns the shortest edit script turning a into b using Myers'
+// algorithm. Each line is prefixed by "  " when unchanged, "- " when removed
+// and "+ " when added
+func lineDiff(a, b []string) []string {
+	n, m := len(a), len(b)
+	max := n + m
+	if max > maxDiffEdits {
+		max = maxDiffEdits
+	}
+
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		// diagonals -d-1 to d+1 are the only ones step d reads from
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+
+	var edits []string
+	for _, line := range a {
+		edits = append(edits, "- "+line)
+	}
+	for _, line := range b {
+		edits = append(edits, "+ "+line)
+	}
+
+	return edits
+}
+
+// backtrack walks the recorded frontiers from the end of both inputs back
+// to the start, emitting the edits in reverse
+func backtrack(trace [][]int, a, b []string) []string {
+	var edits []string
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v, offset := trace[d], d+1
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			edits = append(edits, "  "+a[x-1])
+			x--
+			y--
+		}
+
+		if d == 0 {
+			break
+		}
+
+		if x == prevX {
+			edits = append(edits, "+ "+b[y-1])
+			y--
+		} else {
+			edits = append(edits, "- "+a[x-1])
+			x--
+		}
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+
+	return edits
+}
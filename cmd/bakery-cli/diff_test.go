@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLineDiff(t *testing.T) {
+	segments := numberedLines("seg_%d.ts", maxDiffEdits)
+	rewritten := numberedLines("http://base/seg_%d.ts", maxDiffEdits)
+
+	tests := []struct {
+		name   string
+		a      []string
+		b      []string
+		expect []string
+	}{
+		{
+			name:   "when inputs are equal, every line is unchanged",
+			a:      []string{"#EXTM3U", "link_1.m3u8"},
+			b:      []string{"#EXTM3U", "link_1.m3u8"},
+			expect: []string{"  #EXTM3U", "  link_1.m3u8"},
+		},
+		{
+			name: "when lines are removed, expect them prefixed with -",
+			a:    []string{"#EXTM3U", "#EXT-X-STREAM-INF:BANDWIDTH=1", "link_1.m3u8", "#EXT-X-STREAM-INF:BANDWIDTH=2", "link_2.m3u8"},
+			b:    []string{"#EXTM3U", "#EXT-X-STREAM-INF:BANDWIDTH=2", "link_2.m3u8"},
+			expect: []string{
+				"  #EXTM3U",
+				"- #EXT-X-STREAM-INF:BANDWIDTH=1",
+				"- link_1.m3u8",
+				"  #EXT-X-STREAM-INF:BANDWIDTH=2",
+				"  link_2.m3u8",
+			},
+		},
+		{
+			name:   "when lines are rewritten, expect removal followed by addition",
+			a:      []string{"#EXTM3U", "link_1.m3u8"},
+			b:      []string{"#EXTM3U", "http://base/link_1.m3u8"},
+			expect: []string{"  #EXTM3U", "- link_1.m3u8", "+ http://base/link_1.m3u8"},
+		},
+		{
+			name:   "when first input is empty, every line is added",
+			a:      []string{},
+			b:      []string{"#EXTM3U"},
+			expect: []string{"+ #EXTM3U"},
+		},
+		{
+			name:   "when the edit distance exceeds the limit, expect every line removed then added",
+			a:      segments,
+			b:      rewritten,
+			expect: append(prefixLines("- ", segments), prefixLines("+ ", rewritten)...),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := lineDiff(tc.a, tc.b)
+			if !cmp.Equal(got, tc.expect) {
+				t.Errorf("Wrong diff returned\ngot %v\nexpected: %v\ndiff: %v", got, tc.expect, cmp.Diff(got, tc.expect))
+			}
+		})
+	}
+}
+
+func numberedLines(format string, n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf(format, i)
+	}
+
+	return lines
+}
+
+func prefixLines(prefix string, lines []string) []string {
+	prefixed := make([]string, len(lines))
+	for i, line := range lines {
+		prefixed[i] = prefix + line
+	}
+
+	return prefixed
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/cbsinteractive/bakery"
+	"github.com/cbsinteractive/bakery/parsers"
+)
+
+const usage = `Usage:
+  bakery-cli [flags] <filter-path> [manifest]
+  bakery-cli diff [flags] <filter-path> [manifest]
+
+Reads an HLS, DASH or WebVTT manifest from the given file, or stdin when it is
+omitted or "-", applies the Bakery filter path (e.g. /v(hevc)/b(0,3000000)/)
+and writes the filtered manifest to stdout. The diff subcommand prints the lines
+removed (-) and added (+) by the filters instead.
+
+Flags:
+`
+
+// explanation mirrors the json returned by the explain mode of the server
+type explanation struct {
+	Filters   *bakery.MediaFilters `json:"filters"`
+	OriginURL string               `json:"originURL"`
+	Decisions []bakery.Decision    `json:"decisions"`
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	diff := len(args) > 0 && args[0] == "diff"
+	if diff {
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("bakery-cli", flag.ContinueOnError)
+	baseURL := fs.String("base-url", "", "absolute url of the manifest, used to resolve relative URIs")
+	protocol := fs.String("protocol", "", "protocol of the manifest (hls, dash or vtt), detected when omitted")
+	explain := fs.Bool("explain", false, "print why each rendition was kept or removed as json")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		return fmt.Errorf("expected a filter path and an optional manifest, got %v arguments", fs.NArg())
+	}
+
+	filterPath, manifestPath := fs.Arg(0), fs.Arg(1)
+	content, err := readManifest(manifestPath, stdin)
+	if err != nil {
+		return err
+	}
+
+	p, err := detectProtocol(parsers.Protocol(*protocol), manifestPath, *baseURL, content)
+	if err != nil {
+		return err
+	}
+
+	mediaFilters, err := bakery.ParseFilters(filterPath, p)
+	if err != nil {
+		return fmt.Errorf("parsing filters: %w", err)
+	}
+
+	result, err := bakery.FilterManifest(context.Background(), p, *baseURL, content, mediaFilters, bakery.Options{})
+	if err != nil {
+		return err
+	}
+	filtered := result.Manifest
+
+	switch {
+	case *explain:
+		e := explanation{Filters: mediaFilters, OriginURL: *baseURL, Decisions: []bakery.Decision{}}
+		if result.Decisions != nil {
+			e.Decisions = result.Decisions
+		}
+
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(e)
+	case diff:
+		for _, line := range lineDiff(splitLines(content), splitLines(filtered)) {
+			fmt.Fprintln(stdout, line)
+		}
+		return nil
+	}
+
+	_, err = fmt.Fprint(stdout, filtered)
+	return err
+}
+
+func readManifest(manifestPath string, stdin io.Reader) (string, error) {
+	var content []byte
+	var err error
+	if manifestPath == "" || manifestPath == "-" {
+		content, err = ioutil.ReadAll(stdin)
+	} else {
+		content, err = ioutil.ReadFile(manifestPath)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("reading manifest: %w", err)
+	}
+
+	return string(content), nil
+}
+
+// detectProtocol returns the requested protocol, otherwise the one found in
+// the manifest file name, the base url or the manifest content, in that order
+func detectProtocol(requested parsers.Protocol, manifestPath, baseURL, content string) (parsers.Protocol, error) {
+	switch requested {
+	case parsers.ProtocolHLS, parsers.ProtocolDASH, parsers.ProtocolVTT:
+		return requested, nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported protocol %q", requested)
+	}
+
+	for _, p := range []string{manifestPath, baseURL} {
+		if protocol, found := parsers.ProtocolFromPath(p); found {
+			return protocol, nil
+		}
+	}
+
+	if protocol, found := parsers.ProtocolFromContent(content); found {
+		return protocol, nil
+	}
+
+	return "", errors.New("unable to detect the manifest protocol, set it with --protocol")
+}
+
+func splitLines(s string) []string {
+	return strings.Split(strings.TrimRight(s, "\n"), "\n")
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"sort"
 
 	"github.com/cbsinteractive/bakery/config"
+	"github.com/cbsinteractive/bakery/filters"
 	"github.com/cbsinteractive/bakery/handlers"
 	"github.com/cbsinteractive/pkg/tracing"
 )
@@ -19,11 +23,51 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if err = registerRewriteRules(c.RewriteRules); err != nil {
+		log.Fatal(err)
+	}
+
 	handler := c.SetupMiddleware().Then(handlers.LoadHandler(c))
+	filterHandler := c.SetupMiddleware().Then(handlers.LoadFilterHandler(c))
+
+	// health and readiness probes are registered outside of the middleware
+	// chain so load balancers can reach them without an auth token
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", handlers.LoadHealthHandler())
+	mux.Handle("/readyz", handlers.LoadReadinessHandler(c, handlers.ReadinessChecks(c)...))
+	mux.Handle("/filter", c.Client.Tracer.Handle(tracing.FixedNamer("bakery"), filterHandler))
+	mux.Handle("/", c.Client.Tracer.Handle(tracing.FixedNamer("bakery"), handler))
 
-	c.Logger.Info().Str("port", c.Listen).Str("hostname", c.Hostname).Msg("Starting Bakery")
-	http.Handle("/", c.Client.Tracer.Handle(tracing.FixedNamer("bakery"), handler))
-	if err := http.ListenAndServe(c.Listen, nil); err != nil {
+	c.Logger.Info().Str("port", c.Listen).Str("hostname", c.Hostname).Bool("tls", c.Server.TLSEnabled()).Msg("Starting Bakery")
+	if err := serve(c, newServer(c, mux)); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// registerRewriteRules registers the rewrite rules configured as JSON, such as
+// {"dvs":[{"scheme":"urn:tva:metadata:cs:AudioPurposeCS:2007","role":"description"}]},
+// as plugins selected by name
+func registerRewriteRules(config string) error {
+	if config == "" {
+		return nil
+	}
+
+	var rules map[string][]filters.RewriteRule
+	if err := json.Unmarshal([]byte(config), &rules); err != nil {
+		return fmt.Errorf("parsing rewrite rules: %w", err)
+	}
+
+	var names []string
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := filters.RegisterRewriteRules(name, rules[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
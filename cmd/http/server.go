@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/cbsinteractive/bakery/config"
+)
+
+// newServer builds the http server with the timeouts and limits from config
+func newServer(c config.Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              c.Listen,
+		Handler:           handler,
+		ReadTimeout:       c.Server.ReadTimeout,
+		ReadHeaderTimeout: c.Server.ReadHeaderTimeout,
+		WriteTimeout:      c.Server.WriteTimeout,
+		IdleTimeout:       c.Server.IdleTimeout,
+		MaxHeaderBytes:    c.Server.MaxHeaderBytes,
+	}
+}
+
+// serve starts the server and blocks until it fails or a SIGTERM/SIGINT is
+// received, in which case in-flight requests are drained until the configured
+// shutdown deadline
+func serve(c config.Config, srv *http.Server) error {
+	errs := make(chan error, 1)
+	go func() {
+		var err error
+		if c.Server.TLSEnabled() {
+			err = srv.ListenAndServeTLS(c.Server.TLSCertFile, c.Server.TLSKeyFile)
+		} else {
+			err = srv.ListenAndServe()
+		}
+
+		if !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+		close(errs)
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(stop)
+
+	select {
+	case err := <-errs:
+		return err
+	case sig := <-stop:
+		c.Logger.Info().Str("signal", sig.String()).Dur("deadline", c.Server.ShutdownTimeout).Msg("Shutting down Bakery")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		return err
+	}
+
+	return <-errs
+}
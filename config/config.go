@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/cbsinteractive/bakery/logging"
+	"github.com/cbsinteractive/bakery/parsers"
 	"github.com/justinas/alice"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/rs/zerolog"
@@ -21,12 +22,30 @@ type Config struct {
 	Hostname    string `envconfig:"HOSTNAME"  default:"localhost"`
 	OriginKey   string `encovnfig:"ORIGIN_KEY" default:"x-bakery-origin-token"`
 	OriginToken string `envconfig:"ORIGIN_TOKEN"`
+	ProbeURL    string `envconfig:"PROBE_URL"`
 	Logger      zerolog.Logger
+
+	// RewriteRules holds the JSON rewrite rules registered as plugins, by name
+	RewriteRules string `envconfig:"REWRITE_RULES"`
+	// SessionData is added to every HLS master playlist, parsed from JSON
+	SessionData parsers.SessionDataList `envconfig:"SESSION_DATA"`
+	// ValidationPolicy is how invalid filtered manifests are served
+	ValidationPolicy string `envconfig:"VALIDATION_POLICY" default:"warn"`
+
 	Tracer
 	Client
 	Propeller
+	Server
 }
 
+// Validation policies, serving the origin manifest, a 4xx error or the filtered
+// manifest along with a warning header when a filtered manifest is invalid
+const (
+	ValidationPolicyOriginal = "original"
+	ValidationPolicyReject   = "reject"
+	ValidationPolicyWarn     = "warn"
+)
+
 // LoadConfig loads the configuration with environment variables injected
 func LoadConfig() (Config, error) {
 	var c Config
@@ -37,12 +56,29 @@ func LoadConfig() (Config, error) {
 
 	c.Logger = c.getLogger()
 
+	if err := c.Server.validate(); err != nil {
+		return c, err
+	}
+
+	if err := validatePolicy(c.ValidationPolicy); err != nil {
+		return c, err
+	}
+
 	tracer := c.Tracer.init(c.Logger)
 	c.Client.init(tracer)
 
 	return c, c.Propeller.init(tracer, c.Client.Timeout)
 }
 
+func validatePolicy(policy string) error {
+	switch policy {
+	case ValidationPolicyOriginal, ValidationPolicyReject, ValidationPolicyWarn:
+		return nil
+	}
+
+	return fmt.Errorf("validation policy %q is not supported", policy)
+}
+
 // IsLocalHost returns true if env is localhost
 func (c Config) IsLocalHost() bool {
 	if c.Hostname == "localhost" {
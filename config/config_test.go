@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -83,12 +84,25 @@ func getPropellerConfig(scheme, hostname, usr, pw string, t time.Duration, clien
 	}
 }
 
+// getServerConfig will return the default Server config to use in tests
+func getServerConfig() Server {
+	return Server{
+		ReadTimeout:       10 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		MaxHeaderBytes:    1 << 20,
+		ShutdownTimeout:   30 * time.Second,
+	}
+}
+
 func TestConfig_LoadConfig(t *testing.T) {
 	noopTracer := tracing.NoopTracer{}
 	disabledTraceConfig := getTracerConfig(false, false)
 
 	defaultTime := time.Duration(5 * time.Second)
 	defaultClientConfig := getClientConfig(defaultTime, noopTracer)
+	defaultServerConfig := getServerConfig()
 
 	tests := []struct {
 		name         string
@@ -107,6 +121,9 @@ func TestConfig_LoadConfig(t *testing.T) {
 				Client:      defaultClientConfig,
 				Tracer:      disabledTraceConfig,
 				Propeller:   getPropellerConfig("", "", "", "", time.Duration(0*time.Second), nil),
+				Server:      defaultServerConfig,
+
+				ValidationPolicy: ValidationPolicyWarn,
 			},
 			expectErr: true,
 		},
@@ -125,6 +142,9 @@ func TestConfig_LoadConfig(t *testing.T) {
 				Client:      defaultClientConfig,
 				Tracer:      disabledTraceConfig,
 				Propeller:   getPropellerConfig("http", "propeller.dev.com", "usr", "pw", defaultTime, noopTracer.Client(&http.Client{})),
+				Server:      defaultServerConfig,
+
+				ValidationPolicy: ValidationPolicyWarn,
 			},
 		},
 	}
@@ -294,3 +314,127 @@ func TestConfig_Middleware(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_PropellerPing(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		noHost    bool
+		expectErr bool
+	}{
+		{
+			name:   "when propeller responds with a 4xx, the host is considered reachable",
+			status: http.StatusNotFound,
+		},
+		{
+			name:      "when propeller responds with a 5xx, expect an error",
+			status:    http.StatusBadGateway,
+			expectErr: true,
+		},
+		{
+			name:      "when propeller client is not configured, expect an error",
+			noHost:    true,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			defer ts.Close()
+
+			var p Propeller
+			if !tc.noHost {
+				hostURL, err := url.Parse(ts.URL)
+				if err != nil {
+					t.Fatal(err)
+				}
+				p.Client = propeller.Client{HostURL: hostURL, HTTPClient: ts.Client()}
+			}
+
+			err := p.Ping(context.Background())
+			if err != nil && !tc.expectErr {
+				t.Errorf("Ping() didnt expect an error to be returned, got: %v", err)
+			} else if err == nil && tc.expectErr {
+				t.Error("Ping() expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestConfig_ServerValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		s         Server
+		expectTLS bool
+		expectErr bool
+	}{
+		{
+			name: "Don't throw error when TLS is not configured",
+			s:    Server{},
+		},
+		{
+			name:      "Don't throw error when both TLS certificate and key are set",
+			s:         Server{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem"},
+			expectTLS: true,
+		},
+		{
+			name:      "Throw error when only TLS certificate is set",
+			s:         Server{TLSCertFile: "cert.pem"},
+			expectErr: true,
+		},
+		{
+			name:      "Throw error when only TLS key is set",
+			s:         Server{TLSKeyFile: "key.pem"},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.s.validate()
+
+			if err != nil && !tc.expectErr {
+				t.Errorf("validate() got error did not expect error thrown")
+			} else if err == nil && tc.expectErr {
+				t.Errorf("validate() got no error expected error thrown")
+			}
+
+			if got := tc.s.TLSEnabled(); got != tc.expectTLS {
+				t.Errorf("Wrong TLSEnabled() response\ngot %v\nexpected: %v", got, tc.expectTLS)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidatePolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    string
+		expectErr bool
+	}{
+		{
+			name:   "Don't throw error when the policy is supported",
+			policy: ValidationPolicyReject,
+		},
+		{
+			name:      "Throw error when the policy is not supported",
+			policy:    "ignore",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validatePolicy(tc.policy)
+
+			if err != nil && !tc.expectErr {
+				t.Errorf("validatePolicy() didnt expect an error to be returned, got: %v", err)
+			} else if err == nil && tc.expectErr {
+				t.Error("validatePolicy() expected an error, got nil")
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -41,3 +42,36 @@ func (p *Propeller) init(trace tracing.Tracer, timeout time.Duration) error {
 
 	return nil
 }
+
+// Ping performs a lightweight request against the propeller host to verify it is
+// reachable. Any response below 500 is treated as reachable since the root of the
+// api is not guaranteed to return a success status
+func (p Propeller) Ping(ctx context.Context) error {
+	if p.Client.HostURL == nil || p.Client.HTTPClient == nil {
+		return fmt.Errorf("propeller client not configured")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, p.Client.HostURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("generating propeller ping request: %w", err)
+	}
+	p.Client.Auth.Apply(req)
+
+	if p.Client.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Client.Timeout)
+		defer cancel()
+	}
+
+	resp, err := p.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("pinging propeller: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 > 4 {
+		return fmt.Errorf("pinging propeller: returning http status of %v", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package filters
+
+import (
+	"strings"
+
+	"github.com/zencoder/go-dash/mpd"
+)
+
+const (
+	// forcedSubtitleRole is the DASH-IF role of forced subtitles
+	forcedSubtitleRole = "forced-subtitle"
+
+	cea608Scheme = "urn:scte:dash:cc:cea-608:2015"
+	cea708Scheme = "urn:scte:dash:cc:cea-708:2015"
+)
+
+// captionChannel is a closed caption channel of the CEA-608 or CEA-708
+// Accessibility descriptors of an AdaptationSet, such as CC1=eng
+type captionChannel struct {
+	as      *mpd.AdaptationSet
+	scheme  string
+	channel string
+}
+
+// id returns the instream ID of the channel, such as CC1 or SERVICE2
+func (c captionChannel) id() string {
+	prefix, _ := captionChannelPrefix(c.scheme)
+	return prefix + strings.SplitN(c.channel, "=", 2)[0]
+}
+
+// captionChannelPrefix returns the prefix turning the channel numbers of a
+// closed caption scheme into instream IDs, or false if the scheme is not one
+func captionChannelPrefix(scheme string) (string, bool) {
+	switch scheme {
+	case cea608Scheme:
+		return "", true
+	case cea708Scheme:
+		return "SERVICE", true
+	}
+
+	return "", false
+}
+
+// captionChannels returns the closed caption channels advertised by an AdaptationSet
+func captionChannels(as *mpd.AdaptationSet) []captionChannel {
+	var channels []captionChannel
+	for _, acc := range as.AccessibilityElems {
+		if acc == nil {
+			continue
+		}
+
+		scheme := strval(acc.SchemeIdUri)
+		if _, found := captionChannelPrefix(scheme); !found {
+			continue
+		}
+
+		for _, channel := range strings.Split(strval(acc.Value), ";") {
+			if channel != "" {
+				channels = append(channels, captionChannel{as: as, scheme: scheme, channel: channel})
+			}
+		}
+	}
+
+	return channels
+}
+
+// filterCaptionChannels removes the closed caption channels matching the given
+// instream IDs from the CEA-608 and CEA-708 Accessibility descriptors, such as
+// CC1=eng;CC3=spa or 1=lang:eng;2=lang:spa. Descriptors left without channels
+// are removed while their AdaptationSet, usually carrying the video, is kept
+func filterCaptionChannels(ids []string, manifest *mpd.MPD) {
+	if len(ids) == 0 {
+		return
+	}
+
+	for _, period := range manifest.Periods {
+		for _, as := range period.AdaptationSets {
+			var elems []*mpd.Accessibility
+			for _, acc := range as.AccessibilityElems {
+				if acc == nil {
+					continue
+				}
+
+				prefix, found := captionChannelPrefix(strval(acc.SchemeIdUri))
+				if !found {
+					elems = append(elems, acc)
+					continue
+				}
+
+				var channels []string
+				for _, channel := range strings.Split(strval(acc.Value), ";") {
+					id := strings.SplitN(channel, "=", 2)[0]
+					if channel == "" || (strings.Contains(channel, "=") && matchFold(prefix+id, ids)) {
+						continue
+					}
+					channels = append(channels, channel)
+				}
+
+				if len(channels) > 0 {
+					acc.Value = strptr(strings.Join(channels, ";"))
+					elems = append(elems, acc)
+				}
+			}
+			as.AccessibilityElems = elems
+		}
+	}
+}
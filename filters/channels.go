@@ -0,0 +1,138 @@
+package filters
+
+import (
+	"bufio"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/grafov/m3u8"
+	"github.com/zencoder/go-dash/mpd"
+)
+
+const (
+	mediaTag = "#EXT-X-MEDIA:"
+
+	cicpChannelConfiguration = "urn:mpeg:mpegB:cicp:ChannelConfiguration"
+)
+
+// cicpChannels maps the ChannelConfiguration index of ISO/IEC 23091-3
+// to its number of channels
+var cicpChannels = map[int]int{
+	1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 6: 6, 7: 8, 9: 3, 10: 4, 11: 7,
+	12: 8, 13: 24, 14: 8, 15: 12, 16: 10, 17: 12, 18: 14, 19: 12, 20: 14,
+}
+
+// dolbyChannelPairs is the mask of the Dolby channel configuration bits
+// standing for a pair of channels, such as Lrs/Rrs
+const dolbyChannelPairs = 0x0674
+
+// alternativeKey identifies an HLS media alternative in a master playlist
+type alternativeKey struct {
+	typ     string
+	groupID string
+	name    string
+}
+
+func newAlternativeKey(a *m3u8.Alternative) alternativeKey {
+	return alternativeKey{typ: a.Type, groupID: a.GroupId, name: a.Name}
+}
+
+// mediaChannels returns the CHANNELS attribute of the media alternatives of a
+// master playlist, as the playlist decoder does not keep it
+func mediaChannels(content string, alternatives []*m3u8.Alternative) map[*m3u8.Alternative]string {
+	byKey := map[alternativeKey]string{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, mediaTag) {
+			continue
+		}
+
+		attrs := m3u8.DecodeAttributeList(strings.TrimPrefix(line, mediaTag))
+		if ch, found := attrs["CHANNELS"]; found {
+			byKey[alternativeKey{typ: attrs["TYPE"], groupID: attrs["GROUP-ID"], name: attrs["NAME"]}] = ch
+		}
+	}
+
+	channels := map[*m3u8.Alternative]string{}
+	for _, alt := range alternatives {
+		if ch, found := byKey[newAlternativeKey(alt)]; found {
+			channels[alt] = ch
+		}
+	}
+
+	return channels
+}
+
+// encodedAlternatives returns the media alternatives of the variants in the
+// order the playlist encoder writes them, skipping the ones it deduplicates
+func encodedAlternatives(variants []*m3u8.Variant) []*m3u8.Alternative {
+	var alts []*m3u8.Alternative
+	written := map[string]bool{}
+	for _, v := range variants {
+		for _, alt := range v.Alternatives {
+			key := fmt.Sprintf("%s-%s-%s-%s", alt.Type, alt.GroupId, alt.Name, alt.Language)
+			if written[key] {
+				continue
+			}
+			written[key] = true
+			alts = append(alts, alt)
+		}
+	}
+
+	return alts
+}
+
+// restoreChannels writes the CHANNELS attribute back to the media alternatives
+// of an encoded master playlist. Alternatives are matched on their position so
+// that plugins renaming them do not lose their channels
+func restoreChannels(manifest string, variants []*m3u8.Variant, channels map[*m3u8.Alternative]string) string {
+	if len(channels) == 0 {
+		return manifest
+	}
+
+	alts := encodedAlternatives(variants)
+	lines := strings.Split(manifest, "\n")
+	n := 0
+	for i, line := range lines {
+		if !strings.HasPrefix(line, mediaTag) {
+			continue
+		}
+
+		if n < len(alts) {
+			if ch, found := channels[alts[n]]; found {
+				lines[i] = line + `,CHANNELS="` + ch + `"`
+			}
+		}
+		n++
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// parseChannels returns the channel count of an HLS CHANNELS attribute, such as
+// "6" or "16/JOC" for Dolby Atmos, or 0 if it is missing or invalid
+func parseChannels(channels string) int {
+	return atoi(strings.SplitN(channels, "/", 2)[0])
+}
+
+// channelCount returns the number of channels of a DASH AudioChannelConfiguration,
+// or 0 if its scheme is not supported
+func channelCount(scheme, value *string) int {
+	switch strval(scheme) {
+	case string(mpd.AUDIO_CHANNEL_CONFIGURATION_MPEG_DASH):
+		return atoi(strval(value))
+	case cicpChannelConfiguration:
+		return cicpChannels[atoi(strval(value))]
+	case string(mpd.AUDIO_CHANNEL_CONFIGURATION_MPEG_DOLBY):
+		mask, err := strconv.ParseUint(strval(value), 16, 16)
+		if err != nil {
+			return 0
+		}
+		return bits.OnesCount16(uint16(mask)) + bits.OnesCount16(uint16(mask)&dolbyChannelPairs)
+	}
+
+	return 0
+}
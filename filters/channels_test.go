@@ -0,0 +1,73 @@
+package filters
+
+import (
+	"testing"
+
+	"github.com/zencoder/go-dash/mpd"
+)
+
+func TestChannelCount(t *testing.T) {
+	tests := []struct {
+		name   string
+		scheme string
+		value  string
+		expect int
+	}{
+		{
+			name:   "when the mpeg scheme is used, expect the value as channel count",
+			scheme: string(mpd.AUDIO_CHANNEL_CONFIGURATION_MPEG_DASH),
+			value:  "2",
+			expect: 2,
+		},
+		{
+			name:   "when the cicp scheme signals 7.1, expect 8 channels",
+			scheme: cicpChannelConfiguration,
+			value:  "12",
+			expect: 8,
+		},
+		{
+			name:   "when the dolby scheme signals 5.1, expect 6 channels",
+			scheme: string(mpd.AUDIO_CHANNEL_CONFIGURATION_MPEG_DOLBY),
+			value:  "F801",
+			expect: 6,
+		},
+		{
+			name:   "when the dolby scheme signals 7.1 with rear surround pairs, expect 8 channels",
+			scheme: string(mpd.AUDIO_CHANNEL_CONFIGURATION_MPEG_DOLBY),
+			value:  "FA01",
+			expect: 8,
+		},
+		{
+			name:   "when the scheme is unknown, expect 0",
+			scheme: "urn:unknown",
+			value:  "2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := channelCount(strptr(tt.scheme), strptr(tt.value)); got != tt.expect {
+				t.Errorf("channelCount() wrong count returned\ngot %v\nexpected: %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestParseChannels(t *testing.T) {
+	tests := []struct {
+		channels string
+		expect   int
+	}{
+		{channels: "2", expect: 2},
+		{channels: "16/JOC", expect: 16},
+		{channels: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.channels, func(t *testing.T) {
+			if got := parseChannels(tt.channels); got != tt.expect {
+				t.Errorf("parseChannels() wrong count returned\ngot %v\nexpected: %v", got, tt.expect)
+			}
+		})
+	}
+}
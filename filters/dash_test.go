@@ -58,6 +58,20 @@ func TestDASHFilter_FilterContent_baseURL(t *testing.T) {
 			manifestContent:       manifestWithBaseURL("../some/other/path/"),
 			expectManifestContent: manifestWithBaseURL("http://some.url/to/some/other/path/"),
 		},
+		{
+			name:            "when the manifest URL is empty and no baseURL is set, the manifest is unchanged",
+			manifestURL:     "",
+			manifestContent: manifestWithoutBaseURL,
+			expectManifestContent: `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S"></MPD>
+`,
+		},
+		{
+			name:                  "when the manifest URL is relative and a relative baseURL is set, the baseURL is unchanged",
+			manifestURL:           "to/the/manifest.mpd",
+			manifestContent:       manifestWithBaseURL("../some/other/path/"),
+			expectManifestContent: manifestWithBaseURL("../some/other/path/"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -1172,6 +1186,25 @@ func TestDASHFilter_FilterFrameRate(t *testing.T) {
     </AdaptationSet>
   </Period>
 </MPD>
+`
+
+	manifestWithNoFPSAbove30 := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="en" contentType="video">
+      <Representation bandwidth="2048" codecs="avc" frameRate="30000/1001" id="0"></Representation>
+      <Representation bandwidth="4096" codecs="avc" frameRate="30000/1001" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet frameRate="24000/1001" id="1" lang="en" contentType="video">
+      <Representation bandwidth="2048" codecs="avc" id="0"></Representation>
+      <Representation bandwidth="4096" codecs="avc" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="2" lang="en" contentType="video">
+      <Representation bandwidth="2048" codecs="avc" frameRate="30" id="0"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
 `
 
 	manifestWithNoFrameRates := `<?xml version="1.0" encoding="UTF-8"?>
@@ -1202,6 +1235,22 @@ func TestDASHFilter_FilterFrameRate(t *testing.T) {
 			manifestContent:       manifestWithFrameRates,
 			expectManifestContent: manifestWithOnly60FPS,
 		},
+		{
+			name: "when framerate is set as a decimal, it removes the representations of the matching fraction",
+			filters: &parsers.MediaFilters{
+				FrameRate: []string{"29.97"},
+			},
+			manifestContent:       manifestWithFrameRates,
+			expectManifestContent: manifestWithNo30000FractionFPS,
+		},
+		{
+			name: "when a framerate range up to 30 is set, it removes the representations above",
+			filters: &parsers.MediaFilters{
+				FrameRateRange: &parsers.FrameRateRange{Max: 30},
+			},
+			manifestContent:       manifestWithFrameRates,
+			expectManifestContent: manifestWithNoFPSAbove30,
+		},
 		{
 			name: "when framerate is set with fraction representation, it removes its associated representations",
 			filters: &parsers.MediaFilters{
@@ -1242,6 +1291,538 @@ func TestDASHFilter_FilterFrameRate(t *testing.T) {
 	}
 }
 
+func TestDASHFilter_FilterContent_Expression(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="en" contentType="video">
+      <Representation bandwidth="2048" codecs="hvc1.2.4.L123.B0" frameRate="30000/1001" height="1080" id="0" width="1920"></Representation>
+      <Representation bandwidth="4096" codecs="hvc1.2.4.L150.B0" frameRate="60" height="2160" id="1" width="3840"></Representation>
+      <Representation bandwidth="8192" codecs="hvc1.2.4.L150.B0" frameRate="30000/1001" height="2160" id="2" width="3840"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="es" contentType="audio">
+      <Representation bandwidth="256" codecs="ec-3" id="3">
+        <AudioChannelConfiguration schemeIdUri="urn:mpeg:dash:23003:3:audio_channel_configuration:2011" value="6"></AudioChannelConfiguration>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	tests := []struct {
+		name                  string
+		expression            string
+		expectManifestContent string
+	}{
+		{
+			name:       "when hevc above 1080p is removed unless 60fps, expect only the 60fps 2160p representation kept",
+			expression: `codecs~"hvc" && height>1080 && !(fps==60)`,
+			expectManifestContent: `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="en" contentType="video">
+      <Representation bandwidth="2048" codecs="hvc1.2.4.L123.B0" frameRate="30000/1001" height="1080" id="0" width="1920"></Representation>
+      <Representation bandwidth="4096" codecs="hvc1.2.4.L150.B0" frameRate="60" height="2160" id="1" width="3840"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="es" contentType="audio">
+      <Representation bandwidth="256" codecs="ec-3" id="3">
+        <AudioChannelConfiguration schemeIdUri="urn:mpeg:dash:23003:3:audio_channel_configuration:2011" value="6"></AudioChannelConfiguration>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`,
+		},
+		{
+			name:       "when surround audio is removed, expect the audio adaptation set removed",
+			expression: `type=="audio" && channels>2`,
+			expectManifestContent: `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="en" contentType="video">
+      <Representation bandwidth="2048" codecs="hvc1.2.4.L123.B0" frameRate="30000/1001" height="1080" id="0" width="1920"></Representation>
+      <Representation bandwidth="4096" codecs="hvc1.2.4.L150.B0" frameRate="60" height="2160" id="1" width="3840"></Representation>
+      <Representation bandwidth="8192" codecs="hvc1.2.4.L150.B0" frameRate="30000/1001" height="2160" id="2" width="3840"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := parsers.ParseExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("ParseExpression() didnt expect an error to be returned, got: %v", err)
+			}
+
+			filter := NewDASHFilter("", manifest, config.Config{})
+			got, err := filter.FilterContent(context.Background(), &parsers.MediaFilters{Expressions: []parsers.Expression{e}})
+			if err != nil {
+				t.Fatalf("FilterContent(context.Background(), ) didnt expect an error to be returned, got: %v", err)
+			}
+
+			if g, e := got, tt.expectManifestContent; g != e {
+				t.Errorf("FilterContent(context.Background(), ) wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+					cmp.Diff(g, e))
+			}
+		})
+	}
+}
+
+func TestDASHFilter_FilterContent_VideoRange(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="video">
+      <Representation bandwidth="1000" codecs="avc1.640029" id="0"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" contentType="video">
+      <SupplementalProperty schemeIdUri="urn:mpeg:mpegB:cicp:TransferCharacteristics" value="18"></SupplementalProperty>
+      <Representation bandwidth="2000" codecs="hvc1.2.4.L153.B0" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="2" contentType="video">
+      <Representation bandwidth="3000" codecs="hvc1.2.4.L153.B0" id="2">
+        <SupplementalProperty schemeIdUri="urn:mpeg:mpegB:cicp:TransferCharacteristics" value="16"></SupplementalProperty>
+      </Representation>
+    </AdaptationSet>
+    <AdaptationSet id="3" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="3"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="video">
+      <Representation bandwidth="1000" codecs="avc1.640029" id="0"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="3"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	filter := NewDASHFilter("", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), &parsers.MediaFilters{VideoRanges: []string{"pq", "hlg"}})
+	if err != nil {
+		t.Fatalf("FilterContent(context.Background(), ) didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent(context.Background(), ) wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+			cmp.Diff(g, e))
+	}
+}
+
+func TestDASHFilter_FilterContent_Channels(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="0">
+        <AudioChannelConfiguration schemeIdUri="urn:mpeg:dash:23003:3:audio_channel_configuration:2011" value="2"></AudioChannelConfiguration>
+      </Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" contentType="audio">
+      <Representation bandwidth="384" codecs="ec-3" id="1">
+        <AudioChannelConfiguration schemeIdUri="tag:dolby.com,2014:dash:audio_channel_configuration:2011" value="F801"></AudioChannelConfiguration>
+      </Representation>
+    </AdaptationSet>
+    <AdaptationSet id="2" contentType="audio">
+      <Representation bandwidth="768" codecs="ec-3" id="2">
+        <AudioChannelConfiguration schemeIdUri="urn:mpeg:mpegB:cicp:ChannelConfiguration" value="12"></AudioChannelConfiguration>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="0">
+        <AudioChannelConfiguration schemeIdUri="urn:mpeg:dash:23003:3:audio_channel_configuration:2011" value="2"></AudioChannelConfiguration>
+      </Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" contentType="audio">
+      <Representation bandwidth="384" codecs="ec-3" id="1">
+        <AudioChannelConfiguration schemeIdUri="tag:dolby.com,2014:dash:audio_channel_configuration:2011" value="F801"></AudioChannelConfiguration>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	filter := NewDASHFilter("", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), &parsers.MediaFilters{Audios: parsers.NestedFilters{MaxChannels: 6}})
+	if err != nil {
+		t.Fatalf("FilterContent(context.Background(), ) didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent(context.Background(), ) wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+			cmp.Diff(g, e))
+	}
+}
+
+func TestDASHFilter_FilterContent_CodecProfile(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="video">
+      <Representation bandwidth="1000" codecs="avc1.4d401f" id="0"></Representation>
+      <Representation bandwidth="2000" codecs="avc1.640033" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" contentType="video" codecs="hvc1.2.4.L153.B0">
+      <Representation bandwidth="3000" id="2"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="2" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="3"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="video">
+      <Representation bandwidth="1000" codecs="avc1.4d401f" id="0"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="3"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	filters := &parsers.MediaFilters{Videos: parsers.NestedFilters{CodecConstraints: []parsers.CodecConstraint{
+		{Family: parsers.CodecFamilyAVC, Conditions: []parsers.CodecCondition{{Attribute: "level", Operator: "<=", Value: "4.1"}}},
+		{Family: parsers.CodecFamilyHEVC, Conditions: []parsers.CodecCondition{{Attribute: "profile", Operator: "==", Value: "main"}}},
+	}}}
+
+	filter := NewDASHFilter("", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), filters)
+	if err != nil {
+		t.Fatalf("FilterContent(context.Background(), ) didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent(context.Background(), ) wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+			cmp.Diff(g, e))
+	}
+}
+
+func TestDASHFilter_FilterContent_LanguageTags(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="en-US" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="0"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="eng" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="2" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="2"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="3" lang="spa" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="3"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="4" lang="EN" contentType="text">
+      <Representation bandwidth="256" codecs="wvtt" id="4"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="2"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="spa" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="3"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	filters := &parsers.MediaFilters{
+		Audios:   parsers.NestedFilters{Language: []string{"en"}},
+		Captions: parsers.NestedFilters{Language: []string{"en"}},
+	}
+
+	filter := NewDASHFilter("", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), filters)
+	if err != nil {
+		t.Fatalf("FilterContent(context.Background(), ) didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent(context.Background(), ) wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+			cmp.Diff(g, e))
+	}
+}
+
+func TestDASHFilter_FilterContent_Defaults(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="en" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="main"></Role>
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="0"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="es" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="2" lang="fr" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="dub"></Role>
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="2"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	tests := []struct {
+		name                  string
+		filters               *parsers.MediaFilters
+		expectManifestContent string
+	}{
+		{
+			name:    "when a default language is set, expect its adaptation set given the main role",
+			filters: &parsers.MediaFilters{Defaults: &parsers.Defaults{Audio: []string{"de", "fr"}}},
+			expectManifestContent: `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="en" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="alternate"></Role>
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="0"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="es" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="2" lang="fr" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="dub"></Role>
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="main"></Role>
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="2"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`,
+		},
+		{
+			name:    "when the main adaptation set is filtered out, expect the first remaining one promoted",
+			filters: &parsers.MediaFilters{Audios: parsers.NestedFilters{Language: []string{"en"}}},
+			expectManifestContent: `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="es" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="main"></Role>
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="fr" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="dub"></Role>
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="2"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewDASHFilter("", manifest, config.Config{})
+			got, err := filter.FilterContent(context.Background(), tt.filters)
+			if err != nil {
+				t.Fatalf("FilterContent(context.Background(), ) didnt expect an error to be returned, got: %v", err)
+			}
+
+			if g, e := got, tt.expectManifestContent; g != e {
+				t.Errorf("FilterContent(context.Background(), ) wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+					cmp.Diff(g, e))
+			}
+		})
+	}
+}
+
+func TestDASHFilter_FilterContent_DefaultsFallbackRoles(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="en" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="main"></Role>
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="0"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="es" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="description"></Role>
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="2" lang="es" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="2"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="es" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="description"></Role>
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="es" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="main"></Role>
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="2"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	filters := &parsers.MediaFilters{Audios: parsers.NestedFilters{Language: []string{"en"}}}
+
+	filter := NewDASHFilter("", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), filters)
+	if err != nil {
+		t.Fatalf("FilterContent(context.Background(), ) didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent(context.Background(), ) wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+			cmp.Diff(g, e))
+	}
+}
+
+func TestDASHFilter_FilterContent_CaptionOptions(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="video">
+      <Representation bandwidth="1000" codecs="avc1.640029" id="0"></Representation>
+      <Accessibility schemeIdUri="urn:scte:dash:cc:cea-608:2015" value="CC1=eng;CC3=spa"></Accessibility>
+      <Accessibility schemeIdUri="urn:scte:dash:cc:cea-708:2015" value="2=lang:spa"></Accessibility>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="en" contentType="text">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="subtitle"></Role>
+      <Representation bandwidth="256" codecs="wvtt" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="2" lang="en" contentType="text">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="forced-subtitle"></Role>
+      <Representation bandwidth="256" codecs="wvtt" id="2"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="video">
+      <Representation bandwidth="1000" codecs="avc1.640029" id="0"></Representation>
+      <Accessibility schemeIdUri="urn:scte:dash:cc:cea-608:2015" value="CC1=eng"></Accessibility>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="en" contentType="text">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="subtitle"></Role>
+      <Representation bandwidth="256" codecs="wvtt" id="1"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	filters := &parsers.MediaFilters{Captions: parsers.NestedFilters{
+		Forced:      parsers.ForcedDrop,
+		InstreamIDs: []string{"CC3", "SERVICE2"},
+	}}
+
+	filter := NewDASHFilter("", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), filters)
+	if err != nil {
+		t.Fatalf("FilterContent(context.Background(), ) didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent(context.Background(), ) wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+			cmp.Diff(g, e))
+	}
+}
+
+func TestDASHFilter_FilterContent_Roles(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="en" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="main"></Role>
+      <Representation bandwidth="96000" codecs="mp4a.40.2" id="0"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="en" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="commentary"></Role>
+      <Representation bandwidth="96000" codecs="mp4a.40.2" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="2" lang="en" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="alternate"></Role>
+      <Representation bandwidth="96000" codecs="mp4a.40.2" id="2"></Representation>
+      <Accessibility schemeIdUri="urn:tva:metadata:cs:AudioPurposeCS:2007" value="1"></Accessibility>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="en" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="main"></Role>
+      <Representation bandwidth="96000" codecs="mp4a.40.2" id="0"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	filters := &parsers.MediaFilters{Audios: parsers.NestedFilters{Roles: []string{"commentary", "description"}}}
+
+	filter := NewDASHFilter("", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), filters)
+	if err != nil {
+		t.Fatalf("FilterContent(context.Background(), ) didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent(context.Background(), ) wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+			cmp.Diff(g, e))
+	}
+}
+
 func TestDASHFilter_FilterRole_OverwriteValue(t *testing.T) {
 	manifestWithAccessibilityElement := `<?xml version="1.0" encoding="UTF-8"?>
 <MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
@@ -1254,6 +1835,32 @@ func TestDASHFilter_FilterRole_OverwriteValue(t *testing.T) {
     </AdaptationSet>
   </Period>
 </MPD>
+`
+
+	manifestWithOtherAudioPurpose := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="7357" lang="en" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="alternate"></Role>
+      <Representation bandwidth="256" codecs="ac-3" id="1"></Representation>
+      <Accessibility schemeIdUri="urn:tva:metadata:cs:AudioPurposeCS:2007" value="2"></Accessibility>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	manifestWithOtherAudioPurposeOverwritten := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="7357" lang="en" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="description"></Role>
+      <Representation bandwidth="256" codecs="ac-3" id="1"></Representation>
+      <Accessibility schemeIdUri="urn:tva:metadata:cs:AudioPurposeCS:2007" value="2"></Accessibility>
+    </AdaptationSet>
+  </Period>
+</MPD>
 `
 
 	manifestWithoutAccessibilityElement := `<?xml version="1.0" encoding="UTF-8"?>
@@ -1296,6 +1903,14 @@ func TestDASHFilter_FilterRole_OverwriteValue(t *testing.T) {
 			manifestContent:       manifestWithAccessibilityElement,
 			expectManifestContent: manifestWithOverwrittenRoleValue,
 		},
+		{
+			name: "when proper value is set and the accessibility element has another audio purpose, role value is overwritten.",
+			filters: &parsers.MediaFilters{
+				Plugins: []string{"dvsRoleOverride"},
+			},
+			manifestContent:       manifestWithOtherAudioPurpose,
+			expectManifestContent: manifestWithOtherAudioPurposeOverwritten,
+		},
 		{
 			name: "when proper value is set but no accessibility element is found, role value is not overwritten.",
 			filters: &parsers.MediaFilters{
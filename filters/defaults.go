@@ -0,0 +1,289 @@
+package filters
+
+import (
+	"strings"
+
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/grafov/m3u8"
+	"github.com/zencoder/go-dash/mpd"
+)
+
+const (
+	dashRoleScheme    = "urn:mpeg:dash:role:2011"
+	dashRoleMain      = "main"
+	dashRoleAlternate = "alternate"
+)
+
+// defaultLanguages returns the preferred default languages for the content type
+func defaultLanguages(d *parsers.Defaults, ct ContentType) []string {
+	if d == nil {
+		return nil
+	}
+
+	switch ct {
+	case audioContentType:
+		return d.Audio
+	case captionContentType:
+		return d.Captions
+	}
+
+	return nil
+}
+
+// preferredRendition returns the index of the first rendition matching the
+// earliest preferred language, or -1 if none does. Renditions without an
+// accessibility or commentary role are preferred over the ones with one, so
+// that an audio description track does not become the default
+func preferredRendition(languages []string, renditionLanguages []string, renditionRoles [][]string) int {
+	for _, lang := range languages {
+		match := -1
+		for i, l := range renditionLanguages {
+			if l == "" || !matchLanguage(l, []string{lang}) {
+				continue
+			}
+
+			if !hasSecondaryRole(renditionRoles[i]) {
+				return i
+			}
+
+			if match < 0 {
+				match = i
+			}
+		}
+
+		if match >= 0 {
+			return match
+		}
+	}
+
+	return -1
+}
+
+// fallbackRendition returns the index of the first rendition without an
+// accessibility or commentary role, or the first rendition if all hold one
+func fallbackRendition(renditionRoles [][]string) int {
+	for i, roles := range renditionRoles {
+		if !hasSecondaryRole(roles) {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// hasSecondaryRole returns true if any of the roles flags an accessibility or
+// commentary rendition
+func hasSecondaryRole(roles []string) bool {
+	for _, role := range roles {
+		switch {
+		case role == "description", role == "caption", role == "commentary":
+			return true
+		case strings.HasPrefix(role, "public.accessibility."):
+			return true
+		}
+	}
+
+	return false
+}
+
+type alternativeGroup struct {
+	typ     string
+	groupID string
+}
+
+// selectHLSDefaults sets DEFAULT=YES and AUTOSELECT=YES on the alternative of each
+// group matching the preferred languages, clearing DEFAULT on the others. Groups
+// whose default alternative was filtered out get their first alternative without
+// an accessibility or commentary role promoted
+func selectHLSDefaults(d *parsers.Defaults, original []*m3u8.Alternative, variants []*m3u8.Variant) {
+	hadDefault := map[alternativeGroup]bool{}
+	for _, alt := range original {
+		if alt.Default {
+			hadDefault[alternativeGroup{alt.Type, alt.GroupId}] = true
+		}
+	}
+
+	var groups []alternativeGroup
+	alternatives := map[alternativeGroup][]*m3u8.Alternative{}
+	for _, alt := range uniqueAlternatives(variants) {
+		group := alternativeGroup{alt.Type, alt.GroupId}
+		if _, found := alternatives[group]; !found {
+			groups = append(groups, group)
+		}
+		alternatives[group] = append(alternatives[group], alt)
+	}
+
+	for _, group := range groups {
+		alts := alternatives[group]
+
+		var languages []string
+		var roles [][]string
+		for _, alt := range alts {
+			languages = append(languages, alt.Language)
+			roles = append(roles, alternativeRoles(alt.Characteristics))
+		}
+
+		rendition := newAlternativeRendition(alts[0], "")
+		if i := preferredRendition(defaultLanguages(d, rendition.Type), languages, roles); i >= 0 {
+			setDefaultAlternative(alts, alts[i])
+			continue
+		}
+
+		if hadDefault[group] && !hasDefaultAlternative(alts) {
+			setDefaultAlternative(alts, alts[fallbackRendition(roles)])
+		}
+	}
+}
+
+func setDefaultAlternative(alts []*m3u8.Alternative, selected *m3u8.Alternative) {
+	for _, alt := range alts {
+		alt.Default = alt == selected
+	}
+	selected.Autoselect = "YES"
+}
+
+func hasDefaultAlternative(alts []*m3u8.Alternative) bool {
+	for _, alt := range alts {
+		if alt.Default {
+			return true
+		}
+	}
+
+	return false
+}
+
+// adaptationSetType returns the content type of an AdaptationSet, typed after
+// its codecs or the ones of its first Representation when not set
+func adaptationSetType(as *mpd.AdaptationSet) ContentType {
+	r := newRepresentationRendition(as, nil)
+	if len(as.Representations) > 0 {
+		r = newRepresentationRendition(as, as.Representations[0])
+	}
+
+	if types := r.contentTypes(); len(types) == 1 {
+		return types[0]
+	}
+
+	return ""
+}
+
+// mainRoles returns the content types of each Period with an AdaptationSet
+// holding the main role
+func mainRoles(manifest *mpd.MPD) map[*mpd.Period]map[ContentType]bool {
+	roles := map[*mpd.Period]map[ContentType]bool{}
+	for _, period := range manifest.Periods {
+		roles[period] = map[ContentType]bool{}
+		for _, as := range period.AdaptationSets {
+			if hasMainRole(as) {
+				roles[period][adaptationSetType(as)] = true
+			}
+		}
+	}
+
+	return roles
+}
+
+// selectDASHDefaults adds the main role to the audio and text AdaptationSet of
+// each Period matching the preferred languages, replacing the main role of the
+// others by alternate. Content types whose main AdaptationSet was filtered out
+// get their first AdaptationSet without an accessibility or commentary role
+// promoted
+func selectDASHDefaults(d *parsers.Defaults, manifest *mpd.MPD, hadMain map[*mpd.Period]map[ContentType]bool) {
+	for _, period := range manifest.Periods {
+		for _, ct := range []ContentType{audioContentType, captionContentType} {
+			var adaptationSets []*mpd.AdaptationSet
+			var languages []string
+			var roles [][]string
+			for _, as := range period.AdaptationSets {
+				if adaptationSetType(as) == ct {
+					adaptationSets = append(adaptationSets, as)
+					languages = append(languages, strval(as.Lang))
+					roles = append(roles, adaptationSetRoles(as))
+				}
+			}
+
+			if len(adaptationSets) == 0 {
+				continue
+			}
+
+			if i := preferredRendition(defaultLanguages(d, ct), languages, roles); i >= 0 {
+				setMainRole(adaptationSets, adaptationSets[i])
+				continue
+			}
+
+			if hadMain[period][ct] && !anyMainRole(adaptationSets) {
+				setMainRole(adaptationSets, adaptationSets[fallbackRendition(roles)])
+			}
+		}
+	}
+}
+
+// setMainRole adds a main Role to the selected AdaptationSet next to its
+// existing roles. The main Role of the others is replaced by alternate, or
+// removed when they hold another role in the DASH role scheme
+func setMainRole(adaptationSets []*mpd.AdaptationSet, selected *mpd.AdaptationSet) {
+	for _, as := range adaptationSets {
+		switch {
+		case as == selected && !hasMainRole(as):
+			as.Roles = append(as.Roles, &mpd.Role{SchemeIDURI: strptr(dashRoleScheme), Value: strptr(dashRoleMain)})
+		case as != selected && hasMainRole(as):
+			demoteMainRole(as)
+		}
+	}
+}
+
+func demoteMainRole(as *mpd.AdaptationSet) {
+	var roles []*mpd.Role
+	for _, role := range as.Roles {
+		if isMainRole(role) {
+			continue
+		}
+		roles = append(roles, role)
+	}
+
+	if dashRole(&mpd.AdaptationSet{Roles: roles}) != nil {
+		as.Roles = roles
+		return
+	}
+
+	for _, role := range as.Roles {
+		if isMainRole(role) {
+			role.Value = strptr(dashRoleAlternate)
+		}
+	}
+}
+
+// dashRole returns the first role of the AdaptationSet in the DASH role scheme
+func dashRole(as *mpd.AdaptationSet) *mpd.Role {
+	for _, role := range as.Roles {
+		if role != nil && strval(role.SchemeIDURI) == dashRoleScheme {
+			return role
+		}
+	}
+
+	return nil
+}
+
+func isMainRole(role *mpd.Role) bool {
+	return role != nil && strval(role.SchemeIDURI) == dashRoleScheme && strval(role.Value) == dashRoleMain
+}
+
+func hasMainRole(as *mpd.AdaptationSet) bool {
+	for _, role := range as.Roles {
+		if isMainRole(role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func anyMainRole(adaptationSets []*mpd.AdaptationSet) bool {
+	for _, as := range adaptationSets {
+		if hasMainRole(as) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,129 @@
+package filters
+
+import (
+	"strings"
+
+	"github.com/grafov/m3u8"
+	"github.com/zencoder/go-dash/mpd"
+)
+
+const (
+	keyTag        = "#EXT-X-KEY:"
+	sessionKeyTag = "#EXT-X-SESSION-KEY:"
+
+	// identityKeyFormat is the KEYFORMAT of keys not signaling one, clear
+	// AES-128 keys fetched from the URI
+	identityKeyFormat = "identity"
+)
+
+// drmSchemes maps the ContentProtection scheme of each DRM system, in lower
+// case, to the name used by the drm filter
+var drmSchemes = map[string]string{
+	mpd.CONTENT_PROTECTION_WIDEVINE_SCHEME_ID:       "widevine",
+	mpd.CONTENT_PROTECTION_PLAYREADY_SCHEME_ID:      "playready",
+	mpd.CONTENT_PROTECTION_PLAYREADY_SCHEME_V10_ID:  "playready",
+	"urn:uuid:94ce86fb-07ff-4f43-adb8-93d2fa968ca2": "fairplay",
+	"urn:uuid:e2719d58-a985-b3c9-781a-b030af78d30e": "clearkey",
+	"urn:uuid:1077efec-c0b2-4d02-ace3-3c1e52e2fb4b": "clearkey", //W3C common PSSH
+}
+
+// drmKeyFormats maps the HLS KEYFORMAT of each DRM system, in lower case, to
+// the name used by the drm filter
+var drmKeyFormats = map[string]string{
+	mpd.CONTENT_PROTECTION_WIDEVINE_SCHEME_ID: "widevine",
+	"com.microsoft.playready":                 "playready",
+	"com.apple.streamingkeydelivery":          "fairplay",
+	identityKeyFormat:                         "clearkey",
+	"org.w3.clearkey":                         "clearkey",
+}
+
+// filterContentProtection removes the ContentProtection descriptors of the DRM
+// systems not kept from every AdaptationSet and Representation. The descriptor
+// signaling common encryption, not bound to a DRM system, is always kept
+func filterContentProtection(systems []string, manifest *mpd.MPD) {
+	if len(systems) == 0 {
+		return
+	}
+
+	for _, period := range manifest.Periods {
+		for _, as := range period.AdaptationSets {
+			as.ContentProtection = keptContentProtection(systems, as.ContentProtection)
+			for _, rep := range as.Representations {
+				if rep != nil {
+					rep.ContentProtection = keptContentProtection(systems, rep.ContentProtection)
+				}
+			}
+		}
+	}
+}
+
+func keptContentProtection(systems []string, descriptors []mpd.ContentProtectioner) []mpd.ContentProtectioner {
+	var kept []mpd.ContentProtectioner
+	for _, cp := range descriptors {
+		scheme := strings.ToLower(contentProtectionScheme(cp))
+		if scheme == mpd.CONTENT_PROTECTION_ROOT_SCHEME_ID_URI || matchFold(drmSchemes[scheme], systems) {
+			kept = append(kept, cp)
+		}
+	}
+
+	return kept
+}
+
+// contentProtectionScheme returns the schemeIdUri of a ContentProtection
+// descriptor, whichever type it was decoded into
+func contentProtectionScheme(cp mpd.ContentProtectioner) string {
+	switch cp := cp.(type) {
+	case *mpd.ContentProtection:
+		return strval(cp.SchemeIDURI)
+	case *mpd.CENCContentProtection:
+		return strval(cp.SchemeIDURI)
+	case *mpd.PlayreadyContentProtection:
+		return strval(cp.SchemeIDURI)
+	case *mpd.WidevineContentProtection:
+		return strval(cp.SchemeIDURI)
+	}
+
+	return ""
+}
+
+// filterKeyTags removes the EXT-X-KEY and EXT-X-SESSION-KEY tags whose KEYFORMAT
+// belongs to a DRM system not kept. It works on the playlist text as the decoder
+// only keeps the last key of a segment and drops session keys
+func filterKeyTags(content string, systems []string) string {
+	if len(systems) == 0 {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if keepKeyTag(strings.TrimSpace(line), systems) {
+			kept = append(kept, line)
+		}
+	}
+
+	return strings.Join(kept, "\n")
+}
+
+func keepKeyTag(line string, systems []string) bool {
+	var attrs map[string]string
+	switch {
+	case strings.HasPrefix(line, keyTag):
+		attrs = m3u8.DecodeAttributeList(strings.TrimPrefix(line, keyTag))
+	case strings.HasPrefix(line, sessionKeyTag):
+		attrs = m3u8.DecodeAttributeList(strings.TrimPrefix(line, sessionKeyTag))
+	default:
+		return true
+	}
+
+	if attrs["METHOD"] == "NONE" {
+		return true
+	}
+
+	format := attrs["KEYFORMAT"]
+	if format == "" {
+		format = identityKeyFormat
+	}
+
+	return matchFold(drmKeyFormats[strings.ToLower(format)], systems)
+}
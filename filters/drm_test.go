@@ -0,0 +1,163 @@
+package filters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cbsinteractive/bakery/config"
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDASHFilter_FilterContent_DRM(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" xmlns:cenc="urn:mpeg:cenc:2013" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="video">
+      <ContentProtection schemeIdUri="urn:mpeg:dash:mp4protection:2011" value="cenc" cenc:default_KID="eb676abb-cb34-5e96-bbcf-616630f1a3da"></ContentProtection>
+      <ContentProtection schemeIdUri="urn:uuid:edef8ba9-79d6-4ace-a3c8-27dcd51d21ed"><cenc:pssh>AAAA</cenc:pssh></ContentProtection>
+      <ContentProtection schemeIdUri="urn:uuid:9a04f079-9840-4286-ab92-e65be0885f95"><cenc:pssh>BBBB</cenc:pssh></ContentProtection>
+      <ContentProtection schemeIdUri="urn:uuid:e2719d58-a985-b3c9-781a-b030af78d30e" value="ClearKey1.0"></ContentProtection>
+      <Representation bandwidth="1000" codecs="avc1.640029" id="0">
+        <ContentProtection schemeIdUri="urn:uuid:E2719D58-A985-B3C9-781A-B030AF78D30E" value="ClearKey1.0"></ContentProtection>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	tests := []struct {
+		name    string
+		systems []string
+		expect  string
+	}{
+		{
+			name:    "when widevine is kept, expect the other drm systems to be removed",
+			systems: []string{"widevine"},
+			expect: `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="video">
+      <ContentProtection schemeIdUri="urn:mpeg:dash:mp4protection:2011" cenc:default_KID="eb676abb-cb34-5e96-bbcf-616630f1a3da" value="cenc"></ContentProtection>
+      <ContentProtection schemeIdUri="urn:uuid:edef8ba9-79d6-4ace-a3c8-27dcd51d21ed">
+        <cenc:pssh>AAAA</cenc:pssh>
+      </ContentProtection>
+      <Representation bandwidth="1000" codecs="avc1.640029" id="0"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`,
+		},
+		{
+			name:    "when playready and clearkey are kept, expect widevine to be removed",
+			systems: []string{"playready", "clearkey"},
+			expect: `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="video">
+      <ContentProtection schemeIdUri="urn:mpeg:dash:mp4protection:2011" cenc:default_KID="eb676abb-cb34-5e96-bbcf-616630f1a3da" value="cenc"></ContentProtection>
+      <ContentProtection schemeIdUri="urn:uuid:9a04f079-9840-4286-ab92-e65be0885f95">
+        <cenc:pssh>BBBB</cenc:pssh>
+      </ContentProtection>
+      <ContentProtection schemeIdUri="urn:uuid:e2719d58-a985-b3c9-781a-b030af78d30e" value="ClearKey1.0"></ContentProtection>
+      <Representation bandwidth="1000" codecs="avc1.640029" id="0">
+        <ContentProtection schemeIdUri="urn:uuid:E2719D58-A985-B3C9-781A-B030AF78D30E" value="ClearKey1.0"></ContentProtection>
+      </Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewDASHFilter("", manifest, config.Config{})
+			got, err := filter.FilterContent(context.Background(), &parsers.MediaFilters{DRMSystems: tt.systems})
+			if err != nil {
+				t.Fatalf("FilterContent(context.Background(), ) didnt expect an error to be returned, got: %v", err)
+			}
+
+			if g, e := got, tt.expect; g != e {
+				t.Errorf("FilterContent(context.Background(), ) wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+					cmp.Diff(g, e))
+			}
+		})
+	}
+}
+
+func TestHLSFilter_FilterContent_DRM(t *testing.T) {
+	master := `#EXTM3U
+#EXT-X-VERSION:5
+#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI="skd://key",KEYFORMAT="com.apple.streamingkeydelivery",KEYFORMATVERSIONS="1"
+#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI="data:text/plain;base64,AAAA",KEYFORMAT="urn:uuid:edef8ba9-79d6-4ace-a3c8-27dcd51d21ed",KEYFORMATVERSIONS="1"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/link_1.m3u8
+`
+
+	media := `#EXTM3U
+#EXT-X-VERSION:5
+#EXT-X-TARGETDURATION:6
+#EXT-X-KEY:METHOD=SAMPLE-AES,URI="skd://key",KEYFORMAT="com.apple.streamingkeydelivery",KEYFORMATVERSIONS="1"
+#EXT-X-KEY:METHOD=SAMPLE-AES,URI="https://keys.example.com/key",KEYFORMATVERSIONS="1"
+#EXTINF:6.000,
+http://existing.base/uri/seg_1.ts
+#EXT-X-ENDLIST
+`
+
+	tests := []struct {
+		name     string
+		manifest string
+		filters  *parsers.MediaFilters
+		expect   string
+	}{
+		{
+			name:     "when no drm filter is given, expect the session keys to be kept",
+			manifest: master,
+			filters:  &parsers.MediaFilters{},
+			expect:   master,
+		},
+		{
+			name:     "when fairplay is kept, expect the other session keys to be removed",
+			manifest: master,
+			filters:  &parsers.MediaFilters{DRMSystems: []string{"fairplay"}},
+			expect: `#EXTM3U
+#EXT-X-VERSION:5
+#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI="skd://key",KEYFORMAT="com.apple.streamingkeydelivery",KEYFORMATVERSIONS="1"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/link_1.m3u8
+`,
+		},
+		{
+			name:     "when clearkey is kept, expect keys without a keyformat to be kept in media playlists",
+			manifest: media,
+			filters:  &parsers.MediaFilters{DRMSystems: []string{"clearkey"}},
+			expect: `#EXTM3U
+#EXT-X-VERSION:5
+#EXT-X-TARGETDURATION:6
+#EXT-X-KEY:METHOD=SAMPLE-AES,URI="https://keys.example.com/key",KEYFORMATVERSIONS="1"
+#EXTINF:6.000,
+http://existing.base/uri/seg_1.ts
+#EXT-X-ENDLIST
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewHLSFilter("http://existing.base/uri/master.m3u8", tt.manifest, config.Config{})
+			got, err := filter.FilterContent(context.Background(), tt.filters)
+			if err != nil {
+				t.Fatalf("FilterContent(context.Background(), ) didnt expect an error to be returned, got: %v", err)
+			}
+
+			if g, e := got, tt.expect; g != e {
+				t.Errorf("FilterContent(context.Background(), ) wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+					cmp.Diff(g, e))
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package filters
+
+import (
+	"strconv"
+
+	"github.com/grafov/m3u8"
+	"github.com/zencoder/go-dash/mpd"
+)
+
+// Explainer is implemented by filters that record why each rendition
+// was kept or removed while filtering content
+type Explainer interface {
+	Decisions() []Decision
+}
+
+// RenditionType is the kind of manifest entry a decision refers to
+type RenditionType string
+
+const (
+	variantRendition        RenditionType = "variant"
+	alternativeRendition    RenditionType = "alternative"
+	adaptationSetRendition  RenditionType = "adaptationSet"
+	representationRendition RenditionType = "representation"
+	captionChannelRendition RenditionType = "captionChannel"
+)
+
+// FilterName identifies the filter responsible for removing a rendition
+type FilterName string
+
+const (
+	bitrateFilter      FilterName = "bitrate"
+	videoCodecFilter   FilterName = "videoCodec"
+	codecProfileFilter FilterName = "codecProfile"
+	audioCodecFilter   FilterName = "audioCodec"
+	captionCodecFilter FilterName = "captionCodec"
+	frameRateFilter    FilterName = "fps"
+	videoRangeFilter   FilterName = "videoRange"
+	languageFilter     FilterName = "language"
+	channelsFilter     FilterName = "channels"
+	forcedFilter       FilterName = "forced"
+	instreamIDFilter   FilterName = "instreamID"
+	captionIDFilter    FilterName = "captionChannel"
+	roleFilter         FilterName = "role"
+	contentTypeFilter  FilterName = "contentType"
+	expressionFilter   FilterName = "expression"
+	ladderFilter       FilterName = "ladder"
+	pipelineFilter     FilterName = "pipeline"
+	iFrameFilter       FilterName = "iframe"
+	variantFilter      FilterName = "variant"
+)
+
+// Decision describes whether a rendition was kept and, when removed,
+// which filter removed it
+type Decision struct {
+	Rendition  RenditionType     `json:"rendition"`
+	ID         string            `json:"id"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Kept       bool              `json:"kept"`
+	Filter     FilterName        `json:"filter,omitempty"`
+}
+
+// explainer holds the decisions recorded by a filter
+type explainer struct {
+	decisions []Decision
+}
+
+// Decisions returns the decisions recorded during the last call to FilterContent
+func (e *explainer) Decisions() []Decision {
+	return e.decisions
+}
+
+func (e *explainer) reset() {
+	e.decisions = nil
+}
+
+func (e *explainer) record(d Decision, removedBy FilterName) {
+	d.Kept = removedBy == ""
+	d.Filter = removedBy
+	e.decisions = append(e.decisions, d)
+}
+
+// amend marks the decision recorded at the given index as removed by the filter
+func (e *explainer) amend(i int, removedBy FilterName) {
+	e.decisions[i].Kept = false
+	e.decisions[i].Filter = removedBy
+}
+
+func variantDecision(v *m3u8.Variant) Decision {
+	attrs := map[string]string{
+		"bandwidth": strconv.FormatUint(uint64(v.Bandwidth), 10),
+		"codecs":    v.Codecs,
+	}
+	if v.Resolution != "" {
+		attrs["resolution"] = v.Resolution
+	}
+	if v.FrameRate != 0 {
+		attrs["frameRate"] = strconv.FormatFloat(v.FrameRate, 'f', 3, 64)
+	}
+	if v.Iframe {
+		attrs["iframe"] = "true"
+	}
+
+	return Decision{Rendition: variantRendition, ID: v.URI, Attributes: attrs}
+}
+
+func alternativeDecision(a *m3u8.Alternative) Decision {
+	return Decision{
+		Rendition: alternativeRendition,
+		ID:        a.GroupId + "/" + a.Name,
+		Attributes: map[string]string{
+			"type":     a.Type,
+			"language": a.Language,
+			"uri":      a.URI,
+		},
+	}
+}
+
+func adaptationSetDecision(as *mpd.AdaptationSet) Decision {
+	attrs := map[string]string{}
+	if as.ContentType != nil {
+		attrs["contentType"] = *as.ContentType
+	}
+	if as.Lang != nil {
+		attrs["lang"] = *as.Lang
+	}
+	if as.FrameRate != nil {
+		attrs["frameRate"] = *as.FrameRate
+	}
+
+	return Decision{Rendition: adaptationSetRendition, ID: strval(as.ID), Attributes: attrs}
+}
+
+func representationDecision(as *mpd.AdaptationSet, r *mpd.Representation) Decision {
+	attrs := map[string]string{"adaptationSet": strval(as.ID)}
+	if r.Bandwidth != nil {
+		attrs["bandwidth"] = strconv.FormatInt(*r.Bandwidth, 10)
+	}
+	if r.Codecs != nil {
+		attrs["codecs"] = *r.Codecs
+	}
+	if r.FrameRate != nil {
+		attrs["frameRate"] = *r.FrameRate
+	}
+
+	return Decision{Rendition: representationRendition, ID: strval(r.ID), Attributes: attrs}
+}
+
+func captionChannelDecision(c captionChannel) Decision {
+	attrs := map[string]string{
+		"adaptationSet": strval(c.as.ID),
+		"scheme":        c.scheme,
+		"channel":       c.channel,
+	}
+
+	return Decision{Rendition: captionChannelRendition, ID: c.id(), Attributes: attrs}
+}
+
+func strval(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+// dashRendition is an AdaptationSet or Representation of the origin manifest
+// along with its decision. Decisions are captured before filtering as filters
+// renumber AdaptationSet ids
+type dashRendition struct {
+	key       interface{}
+	decision  Decision
+	removedBy FilterName
+}
+
+// dashRenditions tracks every rendition of a DASH manifest across filter steps
+type dashRenditions []*dashRendition
+
+func newDASHRenditions(manifest *mpd.MPD) dashRenditions {
+	var renditions dashRenditions
+	for _, period := range manifest.Periods {
+		for _, as := range period.AdaptationSets {
+			renditions = append(renditions, &dashRendition{key: as, decision: adaptationSetDecision(as)})
+			for _, r := range as.Representations {
+				renditions = append(renditions, &dashRendition{key: r, decision: representationDecision(as, r)})
+			}
+			for _, c := range captionChannels(as) {
+				renditions = append(renditions, &dashRendition{key: c, decision: captionChannelDecision(c)})
+			}
+		}
+	}
+
+	return renditions
+}
+
+// markRemoved attributes every rendition no longer present in the manifest,
+// and not already removed, to the given filter
+func (d dashRenditions) markRemoved(manifest *mpd.MPD, filter FilterName) {
+	present := map[interface{}]struct{}{}
+	for _, period := range manifest.Periods {
+		for _, as := range period.AdaptationSets {
+			present[as] = struct{}{}
+			for _, r := range as.Representations {
+				present[r] = struct{}{}
+			}
+			for _, c := range captionChannels(as) {
+				present[c] = struct{}{}
+			}
+		}
+	}
+
+	for _, r := range d {
+		if _, found := present[r.key]; !found && r.removedBy == "" {
+			r.removedBy = filter
+		}
+	}
+}
+
+func (d dashRenditions) explain(e *explainer) {
+	for _, r := range d {
+		e.record(r.decision, r.removedBy)
+	}
+}
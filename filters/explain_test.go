@@ -0,0 +1,143 @@
+package filters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cbsinteractive/bakery/config"
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHLSFilter_Decisions(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish",DEFAULT=NO,LANGUAGE="es",URI="audio_es.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="ec3",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="audio_ec3.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2",AUDIO="aac"
+http://existing.base/uri/link_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=4000,CODECS="hvc1.2.4.L93.90,ec-3",AUDIO="ec3"
+http://existing.base/uri/link_2.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=9000,CODECS="avc1.77.30,mp4a.40.2",AUDIO="aac"
+http://existing.base/uri/link_3.m3u8
+`
+
+	filters := &parsers.MediaFilters{
+		Videos: parsers.NestedFilters{
+			Codecs:  []string{"hvc"},
+			Bitrate: &parsers.Bitrate{Min: 0, Max: 5000},
+		},
+		Audios: parsers.NestedFilters{
+			Language: []string{"es"},
+		},
+	}
+
+	expect := []Decision{
+		{Rendition: variantRendition, ID: "http://existing.base/uri/link_1.m3u8", Kept: true},
+		{Rendition: variantRendition, ID: "http://existing.base/uri/link_2.m3u8", Filter: videoCodecFilter},
+		{Rendition: variantRendition, ID: "http://existing.base/uri/link_3.m3u8", Filter: bitrateFilter},
+		{Rendition: alternativeRendition, ID: "aac/English", Kept: true},
+		{Rendition: alternativeRendition, ID: "aac/Spanish", Filter: languageFilter},
+		{Rendition: alternativeRendition, ID: "ec3/English", Filter: variantFilter},
+	}
+
+	filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+	if _, err := filter.FilterContent(context.Background(), filters); err != nil {
+		t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+	}
+
+	got := stripAttributes(filter.Decisions())
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Wrong decisions recorded\ngot %v\nexpected: %v\ndiff: %v", got, expect, cmp.Diff(got, expect))
+	}
+}
+
+func TestDASHFilter_Decisions(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="en" contentType="video">
+      <Representation bandwidth="1000" codecs="avc1.640028" frameRate="30" id="0"></Representation>
+      <Representation bandwidth="9000" codecs="avc1.640028" frameRate="60" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="es" contentType="audio">
+      <Representation bandwidth="256" codecs="mp4a.40.2" id="2"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	filters := &parsers.MediaFilters{
+		FrameRate: []string{"60"},
+		Audios: parsers.NestedFilters{
+			Language: []string{"es"},
+		},
+	}
+
+	expect := []Decision{
+		{Rendition: adaptationSetRendition, ID: "0", Kept: true},
+		{Rendition: representationRendition, ID: "0", Kept: true},
+		{Rendition: representationRendition, ID: "1", Filter: frameRateFilter},
+		{Rendition: adaptationSetRendition, ID: "1", Filter: languageFilter},
+		{Rendition: representationRendition, ID: "2", Filter: languageFilter},
+	}
+
+	filter := NewDASHFilter("", manifest, config.Config{})
+	if _, err := filter.FilterContent(context.Background(), filters); err != nil {
+		t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+	}
+
+	got := stripAttributes(filter.Decisions())
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Wrong decisions recorded\ngot %v\nexpected: %v\ndiff: %v", got, expect, cmp.Diff(got, expect))
+	}
+}
+
+func TestDASHFilter_Decisions_CaptionChannels(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="video">
+      <Representation bandwidth="1000" codecs="avc1.640029" id="0"></Representation>
+      <Accessibility schemeIdUri="urn:scte:dash:cc:cea-608:2015" value="CC1=eng;CC3=spa"></Accessibility>
+      <Accessibility schemeIdUri="urn:scte:dash:cc:cea-708:2015" value="2=lang:spa"></Accessibility>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	filters := &parsers.MediaFilters{Captions: parsers.NestedFilters{InstreamIDs: []string{"CC3", "SERVICE2"}}}
+
+	expect := []Decision{
+		{Rendition: adaptationSetRendition, ID: "0", Kept: true},
+		{Rendition: representationRendition, ID: "0", Kept: true},
+		{Rendition: captionChannelRendition, ID: "CC1", Kept: true},
+		{Rendition: captionChannelRendition, ID: "CC3", Filter: captionIDFilter},
+		{Rendition: captionChannelRendition, ID: "SERVICE2", Filter: captionIDFilter},
+	}
+
+	filter := NewDASHFilter("", manifest, config.Config{})
+	if _, err := filter.FilterContent(context.Background(), filters); err != nil {
+		t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+	}
+
+	got := stripAttributes(filter.Decisions())
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Wrong decisions recorded\ngot %v\nexpected: %v\ndiff: %v", got, expect, cmp.Diff(got, expect))
+	}
+}
+
+// stripAttributes removes the descriptive attributes from decisions so tests
+// can focus on the decision itself
+func stripAttributes(decisions []Decision) []Decision {
+	var stripped []Decision
+	for _, d := range decisions {
+		d.Attributes = nil
+		stripped = append(stripped, d)
+	}
+
+	return stripped
+}
@@ -28,6 +28,7 @@ type CodecFilterID string
 
 const (
 	hevcCodec  CodecFilterID = "hvc"
+	hev1Codec  CodecFilterID = "hev1"
 	avcCodec   CodecFilterID = "avc"
 	dolbyCodec CodecFilterID = "dvh"
 	aacCodec   CodecFilterID = "mp4a"
@@ -49,9 +50,10 @@ func isAudioCodec(codec string) bool {
 		ValidCodecs(codec, ac3Codec))
 }
 
-// Returns true if given codec is a video codec (hvc, avc, or dvh)
+// Returns true if given codec is a video codec (hvc, hev1, avc, or dvh)
 func isVideoCodec(codec string) bool {
 	return (ValidCodecs(codec, hevcCodec) ||
+		ValidCodecs(codec, hev1Codec) ||
 		ValidCodecs(codec, avcCodec) ||
 		ValidCodecs(codec, dolbyCodec))
 }
@@ -0,0 +1,148 @@
+package filters
+
+import (
+	"strings"
+
+	"github.com/grafov/m3u8"
+)
+
+// noClosedCaptions is the CLOSED-CAPTIONS value of variants without captions
+const noClosedCaptions = "NONE"
+
+// tidyAlternativeGroups makes the variants and their alternatives consistent
+// once filtered: alternatives of groups the variant no longer references are
+// removed, references to groups left without alternatives are cleared and
+// identical alternatives are only kept once
+func tidyAlternativeGroups(variants []*m3u8.Variant) {
+	var unique []*m3u8.Alternative
+	for _, v := range variants {
+		var alts []*m3u8.Alternative
+		groups := map[alternativeGroup]struct{}{}
+		for _, alt := range v.Alternatives {
+			if alt == nil || alt.GroupId != variantGroup(v, alt.Type) {
+				continue
+			}
+
+			alt = identicalAlternative(&unique, alt)
+			if containsAlternative(alts, alt) {
+				continue
+			}
+
+			alts = append(alts, alt)
+			groups[alternativeGroup{alt.Type, alt.GroupId}] = struct{}{}
+		}
+
+		v.Alternatives = alts
+		if len(alts) == 0 {
+			v.Alternatives = nil
+		}
+
+		for _, typ := range []string{"AUDIO", "VIDEO", "SUBTITLES", "CLOSED-CAPTIONS"} {
+			groupID := variantGroup(v, typ)
+			if groupID == "" || (typ == "CLOSED-CAPTIONS" && groupID == noClosedCaptions) {
+				continue
+			}
+
+			if _, found := groups[alternativeGroup{typ, groupID}]; !found {
+				setVariantGroup(v, typ, "")
+			}
+		}
+	}
+}
+
+// identicalAlternative returns the first alternative seen identical to the
+// given one, the given one being recorded as seen when none is
+func identicalAlternative(seen *[]*m3u8.Alternative, alt *m3u8.Alternative) *m3u8.Alternative {
+	for _, s := range *seen {
+		if s == alt || *s == *alt {
+			return s
+		}
+	}
+
+	*seen = append(*seen, alt)
+	return alt
+}
+
+func containsAlternative(alts []*m3u8.Alternative, alt *m3u8.Alternative) bool {
+	for _, a := range alts {
+		if a == alt {
+			return true
+		}
+	}
+
+	return false
+}
+
+// variantGroup returns the group the variant references for the media type
+func variantGroup(v *m3u8.Variant, typ string) string {
+	switch typ {
+	case "AUDIO":
+		return v.Audio
+	case "VIDEO":
+		return v.Video
+	case "SUBTITLES":
+		return v.Subtitles
+	case "CLOSED-CAPTIONS":
+		return v.Captions
+	}
+
+	return ""
+}
+
+func setVariantGroup(v *m3u8.Variant, typ, groupID string) {
+	switch typ {
+	case "AUDIO":
+		v.Audio = groupID
+	case "VIDEO":
+		v.Video = groupID
+	case "SUBTITLES":
+		v.Subtitles = groupID
+	case "CLOSED-CAPTIONS":
+		v.Captions = groupID
+	}
+}
+
+// masterVersion returns the lowest EXT-X-VERSION supporting the features used
+// by the master playlist and its session tags. Version 4 is kept for alternatives,
+// as the playlist writer does. Session keys signaling an
+// IV require version 2 and ones using SAMPLE-AES, KEYFORMAT or KEYFORMATVERSIONS
+// version 5. SERVICE values of INSTREAM-ID require version 7
+func masterVersion(playlist *m3u8.MasterPlaylist, sessionTags []string) uint8 {
+	version := uint8(3)
+	raise := func(v uint8) {
+		if version < v {
+			version = v
+		}
+	}
+
+	for _, v := range playlist.Variants {
+		for _, alt := range v.Alternatives {
+			raise(4)
+			if strings.HasPrefix(alt.InstreamID, "SERVICE") {
+				raise(7)
+			}
+		}
+	}
+
+	for _, tag := range sessionTags {
+		if strings.HasPrefix(tag, sessionKeyTag) {
+			raise(keyVersion(m3u8.DecodeAttributeList(strings.TrimPrefix(tag, sessionKeyTag))))
+		}
+	}
+
+	return version
+}
+
+// keyVersion returns the lowest EXT-X-VERSION supporting the attributes of a key
+func keyVersion(attrs map[string]string) uint8 {
+	_, keyFormat := attrs["KEYFORMAT"]
+	_, keyFormatVersions := attrs["KEYFORMATVERSIONS"]
+	switch {
+	case keyFormat || keyFormatVersions || attrs["METHOD"] == "SAMPLE-AES":
+		return 5
+	case attrs["IV"] != "":
+		return 2
+	}
+
+	return 1
+}
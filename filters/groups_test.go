@@ -0,0 +1,165 @@
+package filters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cbsinteractive/bakery/config"
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafov/m3u8"
+)
+
+func TestTidyAlternativeGroups(t *testing.T) {
+	english := &m3u8.Alternative{Type: "AUDIO", GroupId: "aac", Name: "English", Language: "en"}
+	duplicate := &m3u8.Alternative{Type: "AUDIO", GroupId: "aac", Name: "English", Language: "en"}
+	subtitles := &m3u8.Alternative{Type: "SUBTITLES", GroupId: "subs", Name: "English", Language: "en"}
+
+	tests := []struct {
+		name   string
+		params m3u8.VariantParams
+		expect m3u8.VariantParams
+	}{
+		{
+			name: "when alternatives are identical, expect them kept once",
+			params: m3u8.VariantParams{
+				Audio:        "aac",
+				Alternatives: []*m3u8.Alternative{english, duplicate},
+			},
+			expect: m3u8.VariantParams{
+				Audio:        "aac",
+				Alternatives: []*m3u8.Alternative{english},
+			},
+		},
+		{
+			name: "when an alternative group is not referenced by the variant, expect it removed",
+			params: m3u8.VariantParams{
+				Audio:        "aac",
+				Alternatives: []*m3u8.Alternative{english, subtitles},
+			},
+			expect: m3u8.VariantParams{
+				Audio:        "aac",
+				Alternatives: []*m3u8.Alternative{english},
+			},
+		},
+		{
+			name: "when a referenced group has no alternatives, expect the reference cleared",
+			params: m3u8.VariantParams{
+				Audio:        "aac",
+				Subtitles:    "subs",
+				Video:        "video",
+				Alternatives: []*m3u8.Alternative{english},
+			},
+			expect: m3u8.VariantParams{
+				Audio:        "aac",
+				Alternatives: []*m3u8.Alternative{english},
+			},
+		},
+		{
+			name:   "when closed captions are none, expect the reference kept",
+			params: m3u8.VariantParams{Captions: "NONE"},
+			expect: m3u8.VariantParams{Captions: "NONE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &m3u8.Variant{VariantParams: tt.params}
+			tidyAlternativeGroups([]*m3u8.Variant{v})
+
+			if diff := cmp.Diff(v.VariantParams, tt.expect); diff != "" {
+				t.Errorf("tidyAlternativeGroups() wrong variant params\ndiff: %v", diff)
+			}
+		})
+	}
+}
+
+func TestMasterVersion(t *testing.T) {
+	tests := []struct {
+		name         string
+		alternatives []*m3u8.Alternative
+		sessionTags  []string
+		expect       uint8
+	}{
+		{
+			name:   "when the playlist has no alternatives, expect version 3",
+			expect: 3,
+		},
+		{
+			name:         "when the playlist has alternatives, expect version 4",
+			alternatives: []*m3u8.Alternative{{Type: "CLOSED-CAPTIONS", InstreamID: "CC1"}},
+			expect:       4,
+		},
+		{
+			name:         "when closed captions use a service instream id, expect version 7",
+			alternatives: []*m3u8.Alternative{{Type: "CLOSED-CAPTIONS", InstreamID: "SERVICE1"}},
+			expect:       7,
+		},
+		{
+			name:        "when a session key signals a keyformat, expect version 5",
+			sessionTags: []string{`#EXT-X-SESSION-KEY:METHOD=AES-128,URI="https://keys.example.com/key",KEYFORMAT="identity"`},
+			expect:      5,
+		},
+		{
+			name:        "when a session key uses sample-aes, expect version 5",
+			sessionTags: []string{`#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI="https://keys.example.com/key"`},
+			expect:      5,
+		},
+		{
+			name:        "when a session key only signals an iv, expect version 3",
+			sessionTags: []string{`#EXT-X-SESSION-KEY:METHOD=AES-128,URI="https://keys.example.com/key",IV=0x1234`},
+			expect:      3,
+		},
+		{
+			name:         "when session keys and service instream ids are used, expect the highest version",
+			alternatives: []*m3u8.Alternative{{Type: "CLOSED-CAPTIONS", InstreamID: "SERVICE1"}},
+			sessionTags:  []string{`#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI="skd://key",KEYFORMAT="com.apple.streamingkeydelivery"`},
+			expect:       7,
+		},
+		{
+			name:        "when only session data is used, expect version 3",
+			sessionTags: []string{`#EXT-X-SESSION-DATA:DATA-ID="com.example.title",VALUE="Discovery"`},
+			expect:      3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playlist := m3u8.NewMasterPlaylist()
+			playlist.Variants = []*m3u8.Variant{{VariantParams: m3u8.VariantParams{Alternatives: tt.alternatives}}}
+
+			if got := masterVersion(playlist, tt.sessionTags); got != tt.expect {
+				t.Errorf("masterVersion() = %v, expected %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestHLSFilter_FilterContent_TidyGroups(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="ec3",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en_ec3.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2",AUDIO="aac",SUBTITLES="subs",CLOSED-CAPTIONS=NONE
+http://existing.base/uri/link_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,ec-3",AUDIO="ec3",CLOSED-CAPTIONS=NONE
+http://existing.base/uri/link_2.m3u8
+`
+
+	expect := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2",AUDIO="aac",CLOSED-CAPTIONS=NONE
+http://existing.base/uri/link_1.m3u8
+`
+
+	filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), &parsers.MediaFilters{Audios: parsers.NestedFilters{Codecs: []string{"ec-3"}}})
+	if err != nil {
+		t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e, cmp.Diff(g, e))
+	}
+}
@@ -3,7 +3,6 @@ package filters
 import (
 	"context"
 	"encoding/base64"
-	"errors"
 	"fmt"
 	"net/url"
 	"path/filepath"
@@ -16,17 +15,16 @@ import (
 	"github.com/grafov/m3u8"
 )
 
-type execPluginHLS func(variant *m3u8.Variant)
-
 const EmptyHLSManifestContent = "#EXTM3U"
 
 // HLSFilter implements the Filter interface for HLS
 // manifests
 type HLSFilter struct {
-	originURL     string
-	originContent string
-	maxSegmentSize  float64
-	config          config.Config
+	originURL      string
+	originContent  string
+	maxSegmentSize float64
+	config         config.Config
+	explainer
 }
 
 var matchFunctions = map[ContentType]func(string) bool{
@@ -59,16 +57,24 @@ func (h *HLSFilter) GetMaxAge() string {
 // FilterContent will be responsible for filtering the manifest
 // according  to the MediaFilters
 func (h *HLSFilter) FilterContent(ctx context.Context, filters *parsers.MediaFilters) (string, error) {
-	m, manifestType, err := m3u8.DecodeFrom(strings.NewReader(h.originContent), true)
+	h.reset()
+
+	content := filterKeyTags(h.originContent, filters.DRMSystems)
+	m, manifestType, err := m3u8.DecodeFrom(strings.NewReader(content), true)
 	if err != nil {
 		return "", err
 	}
 
 	if manifestType != m3u8.MASTER {
+		playlist := m.(*m3u8.MediaPlaylist)
 		if filters.Trim != nil {
-			return h.trimRenditionManifest(filters, m.(*m3u8.MediaPlaylist))
+			return h.trimRenditionManifest(filters, playlist)
+		}
+
+		if runMediaPlugins(filters.Plugins, playlist) {
+			return playlist.Encode().String(), nil
 		}
-		return isEmpty(h.originContent)
+		return isEmpty(content)
 	}
 
 	// convert into the master playlist type
@@ -91,12 +97,22 @@ func (h *HLSFilter) FilterContent(ctx context.Context, filters *parsers.MediaFil
 	//with each variant refrencing it. We hold a slice of trimmed
 	//alternatives to avoid processing a media alternative twice
 	trimmedAlternatives := make(map[string]struct{})
+	alternatives := uniqueAlternatives(manifest.Variants)
+	removedAlternatives := make(map[*m3u8.Alternative]FilterName)
+	steps := enabledSteps(filters, parsers.ProtocolHLS)
+	channels := mediaChannels(h.originContent, alternatives)
+
+	// variants kept by the filter steps, along with the index of their decision
+	var kept []*m3u8.Variant
+	decisions := make(map[*m3u8.Variant]int)
 	for i, v := range manifest.Variants {
 		if !isValidPipeline(pipeline, i) {
+			h.record(variantDecision(v), pipelineFilter)
 			continue
 		}
 
 		if filters.SuppressIFrame() && v.Iframe {
+			h.record(variantDecision(v), iFrameFilter)
 			continue
 		}
 
@@ -110,16 +126,35 @@ func (h *HLSFilter) FilterContent(ctx context.Context, filters *parsers.MediaFil
 			return "", err
 		}
 
-		filteredVariant, err := h.filterVariant(filters, normalizedVariant)
-		if err != nil {
-			return "", err
+		removedBy := filterVariant(steps, filters, normalizedVariant)
+		if removedBy == "" {
+			// Alternatives are only filtered for the variants kept
+			removedBy = filterVariantAlternatives(steps, filters, normalizedVariant, channels, removedAlternatives)
 		}
 
-		if filteredVariant {
+		h.record(variantDecision(normalizedVariant), removedBy)
+		if removedBy != "" {
 			continue
 		}
 
-		uri := normalizedVariant.URI
+		decisions[normalizedVariant] = len(h.decisions) - 1
+		kept = append(kept, normalizedVariant)
+	}
+
+	// The ladder is shaped last, once every other filter removed its variants
+	removedByLadder := ladderVariants(filters.Ladder, kept)
+	var firstVariant string
+	for _, v := range orderVariants(filters.Sort, filters.First, kept) {
+		if _, removed := removedByLadder[v]; removed {
+			h.amend(decisions[v], ladderFilter)
+			continue
+		}
+
+		if firstVariant == "" && !v.Iframe {
+			firstVariant = v.URI
+		}
+
+		uri := v.URI
 		if filters.Trim != nil {
 			uri, err = h.normalizeTrimmedVariant(filters, uri)
 			if err != nil {
@@ -131,212 +166,178 @@ func (h *HLSFilter) FilterContent(ctx context.Context, filters *parsers.MediaFil
 			}
 		}
 
-		filteredManifest.Append(uri, normalizedVariant.Chunklist, normalizedVariant.VariantParams)
+		filteredManifest.Append(uri, v.Chunklist, v.VariantParams)
 	}
 
-	return filteredManifest.String(), nil
-}
-
-func (h *HLSFilter) filterPipeline(ctx context.Context, uri string) (pipelineType, error) {
-	absolute, err := getAbsoluteURL(h.originURL)
-	if err != nil {
-		return "", fmt.Errorf("formatting segment URLs: %w", err)
-	}
+	h.explainAlternatives(alternatives, removedAlternatives, filteredManifest.Variants)
+	tidyAlternativeGroups(filteredManifest.Variants)
+	selectHLSDefaults(filters.Defaults, alternatives, filteredManifest.Variants)
 
-	uri, err = combinedIfRelative(uri, *absolute)
-	if err != nil {
-		return "", fmt.Errorf("formatting segment URLs: %w", err)
+	for _, plugin := range hlsPlugins(filters.Plugins) {
+		if plugin.Master != nil {
+			plugin.Master(filteredManifest)
+		}
 	}
 
-	healthy, err := healthCheckVariant(ctx, uri, h.config.Client)
+	sessionTags, err := h.sessionTags(ctx, filters, content, firstVariant)
 	if err != nil {
 		return "", err
 	}
+	filteredManifest.SetVersion(masterVersion(filteredManifest, sessionTags))
 
-	if healthy {
-		return primaryPipeline, nil
-	}
-
-	return backupPipeline, nil
+	return restoreSessionTags(restoreChannels(filteredManifest.String(), filteredManifest.Variants, channels), sessionTags), nil
 }
 
-// Returns true if specified variant should be removed from filter
-func (h *HLSFilter) filterVariant(filters *parsers.MediaFilters, v *m3u8.Variant) (bool, error) {
-	variantCodecs := strings.Split(v.Codecs, ",")
-
-	if filters.Videos.Bitrate != nil || filters.Audios.Bitrate != nil {
-		if h.filterVariantBandwidth(int(v.VariantParams.Bandwidth), variantCodecs, filters) {
-			return true, nil
+// runMediaPlugins runs the media function of the selected plugins on the
+// playlist. Returns true if any plugin ran
+func runMediaPlugins(names []string, playlist *m3u8.MediaPlaylist) bool {
+	var ran bool
+	for _, plugin := range hlsPlugins(names) {
+		if plugin.Media != nil {
+			plugin.Media(playlist)
+			ran = true
 		}
 	}
 
-	if filters.Videos.Codecs != nil {
-		supportedVideoTypes := map[string]struct{}{}
-		for _, vt := range filters.Videos.Codecs {
-			supportedVideoTypes[string(vt)] = struct{}{}
-		}
-		res, err := filterVariantCodecs(videoContentType, variantCodecs, supportedVideoTypes, matchFunctions)
-		if res {
-			return true, err
-		}
-	}
+	return ran
+}
 
-	if filters.Audios.Codecs != nil {
-		supportedAudioTypes := map[string]struct{}{}
-		for _, at := range filters.Audios.Codecs {
-			supportedAudioTypes[string(at)] = struct{}{}
-		}
-		res, err := filterVariantCodecs(audioContentType, variantCodecs, supportedAudioTypes, matchFunctions)
-		if res {
-			return true, err
+// explainAlternatives records a decision for every media alternative of the origin
+// manifest. Alternatives not referenced by a remaining variant were either removed
+// by a filter step or dropped alongside the variants referencing them
+func (h *HLSFilter) explainAlternatives(alternatives []*m3u8.Alternative, removed map[*m3u8.Alternative]FilterName, variants []*m3u8.Variant) {
+	kept := map[*m3u8.Alternative]struct{}{}
+	for _, v := range variants {
+		for _, alt := range v.Alternatives {
+			kept[alt] = struct{}{}
 		}
 	}
 
-	if filters.Captions.Codecs != nil {
-		supportedCaptions := map[string]struct{}{}
-		for _, ct := range filters.Captions.Codecs {
-			supportedCaptions[string(ct)] = struct{}{}
-		}
-		res, err := filterVariantCodecs(captionContentType, variantCodecs, supportedCaptions, matchFunctions)
-		if res {
-			return true, err
-		}
-	}
-
-	if filters.FrameRate != nil {
-		if filterVariantFrameRate(v.FrameRate, filters.FrameRate) {
-			return true, nil
+	for _, alt := range alternatives {
+		var removedBy FilterName
+		if _, found := kept[alt]; !found {
+			removedBy = variantFilter
+			if name, found := removed[alt]; found {
+				removedBy = name
+			}
 		}
+		h.record(alternativeDecision(alt), removedBy)
 	}
-
-	// This filter should run last as it is not removing variants, rather updating the alternatives attached to
-	// the variant. This function will only execute if no matches have been found
-	if filters.Audios.Language != nil || filters.Captions.Language != nil {
-		h.filterVariantLanguage(v, filters)
-	}
-
-	return false, nil
 }
 
-// Returns true if the provided variant is out of range since filters are removed when true.
-func (h *HLSFilter) filterVariantBandwidth(b int, variantCodecs []string, filters *parsers.MediaFilters) bool {
-	for _, codec := range variantCodecs {
-		var min, max int
-
-		switch {
-		case isAudioCodec(codec):
-			if filters.Audios.Bitrate == nil {
+// uniqueAlternatives returns the media alternatives referenced by the variants
+// in order of appearance, each alternative only being returned once
+func uniqueAlternatives(variants []*m3u8.Variant) []*m3u8.Alternative {
+	var alternatives []*m3u8.Alternative
+	seen := map[*m3u8.Alternative]struct{}{}
+	for _, v := range variants {
+		for _, alt := range v.Alternatives {
+			if _, found := seen[alt]; found {
 				continue
 			}
-
-			min = filters.Audios.Bitrate.Min
-			max = filters.Audios.Bitrate.Max
-		case isVideoCodec(codec):
-			if filters.Videos.Bitrate == nil {
-				continue
-			}
-
-			min = filters.Videos.Bitrate.Min
-			max = filters.Videos.Bitrate.Max
-		default:
-			continue
-		}
-
-		if !inRange(min, max, b) {
-			return true
+			seen[alt] = struct{}{}
+			alternatives = append(alternatives, alt)
 		}
 	}
 
-	return false
+	return alternatives
 }
 
-// Returns true if the given variant (variantCodecs) should be filtered out
-func filterVariantCodecs(filterType ContentType, variantCodecs []string, supportedCodecs map[string]struct{}, supportedFilterTypes map[ContentType]func(string) bool) (bool, error) {
-	var matchFilterType func(string) bool
+func (h *HLSFilter) filterPipeline(ctx context.Context, uri string) (pipelineType, error) {
+	absolute, err := getAbsoluteURL(h.originURL)
+	if err != nil {
+		return "", fmt.Errorf("formatting segment URLs: %w", err)
+	}
 
-	matchFilterType, found := supportedFilterTypes[filterType]
+	uri, err = combinedIfRelative(uri, *absolute)
+	if err != nil {
+		return "", fmt.Errorf("formatting segment URLs: %w", err)
+	}
 
-	if !found {
-		return false, errors.New("filter type is unsupported")
+	healthy, err := healthCheckVariant(ctx, uri, h.config.Client)
+	if err != nil {
+		return "", err
 	}
 
-	variantFound := false
-	for _, codec := range variantCodecs {
-		if matchFilterType(codec) {
-			for sc := range supportedCodecs {
-				if ValidCodecs(codec, CodecFilterID(sc)) {
-					variantFound = true
-					break
-				}
-			}
-		}
+	if healthy {
+		return primaryPipeline, nil
 	}
 
-	return variantFound, nil
+	return backupPipeline, nil
 }
 
-func filterVariantFrameRate(floatFPS float64, frameRates []string) bool {
-	strFPS := fmt.Sprintf("%.3f", floatFPS)
-
-	for _, fr := range frameRates {
-		if strFPS == string(fr) {
-			return true
+// Returns the name of the step removing the specified variant, or an
+// empty string if the variant should be kept
+func filterVariant(steps []filterStep, filters *parsers.MediaFilters, v *m3u8.Variant) FilterName {
+	r := newVariantRendition(v)
+	for _, step := range steps {
+		if step.remove(filters, r) {
+			return step.name
 		}
 	}
 
-	return false
+	return ""
 }
 
-// Returns true if a given variant matches the provided language filter
-func (h *HLSFilter) filterVariantLanguage(v *m3u8.Variant, filters *parsers.MediaFilters) {
-	if v.Alternatives == nil {
-		return
-	}
-
-	match := func(alt *m3u8.Alternative, langs []string) bool {
-		if langs == nil {
-			return false
-		}
-
-		for _, lang := range langs {
-			if strings.EqualFold(string(lang), alt.Language) {
-				return true
-			}
-		}
-
-		return false
+// Removes the alternatives of the variant matched by a step, recording the step
+// removing them, and clears the variant references to groups left empty. Returns
+// the name of the step removing the variant when it emptied its audio group and
+// prunes variants, or an empty string if the variant should be kept
+func filterVariantAlternatives(steps []filterStep, filters *parsers.MediaFilters, v *m3u8.Variant, channels map[*m3u8.Alternative]string, removed map[*m3u8.Alternative]FilterName) FilterName {
+	if v.Alternatives == nil || len(steps) == 0 {
+		return ""
 	}
 
 	var alts []*m3u8.Alternative
 	var groupIDs = map[string]struct{}{}
+	var pruneAudio FilterName
 	for _, alt := range v.Alternatives {
-		remove := true
-		switch alt.Type {
-		case "AUDIO":
-			remove = match(alt, filters.Audios.Language)
-		case "SUBTITLES":
-			remove = match(alt, filters.Captions.Language)
-		case "CLOSED-CAPTIONS":
-			remove = match(alt, filters.Captions.Language)
+		if step := filterAlternative(steps, filters, alt, channels[alt]); step != nil {
+			removed[alt] = step.name
+			if step.pruneVariants && alt.Type == "AUDIO" && alt.GroupId == v.Audio {
+				pruneAudio = step.name
+			}
+			continue
 		}
 
-		if !remove {
-			alts = append(alts, alt)
-			groupIDs[alt.GroupId] = struct{}{}
-		}
+		alts = append(alts, alt)
+		groupIDs[alt.GroupId] = struct{}{}
+	}
 
+	if len(alts) == len(v.Alternatives) {
+		return ""
 	}
 
 	v.Alternatives = alts
 	if _, audio := groupIDs[v.Audio]; !audio {
+		if pruneAudio != "" {
+			return pruneAudio
+		}
 		v.Audio = ""
 	}
+	if _, video := groupIDs[v.Video]; !video {
+		v.Video = ""
+	}
 	if _, subs := groupIDs[v.Subtitles]; !subs {
 		v.Subtitles = ""
 	}
 	if _, captions := groupIDs[v.Captions]; !captions {
 		v.Captions = ""
 	}
+
+	return ""
+}
+
+// Returns the step removing the alternative, or nil if it should be kept
+func filterAlternative(steps []filterStep, filters *parsers.MediaFilters, alt *m3u8.Alternative, channels string) *filterStep {
+	r := newAlternativeRendition(alt, channels)
+	for i := range steps {
+		if steps[i].remove(filters, r) {
+			return &steps[i]
+		}
+	}
+
+	return nil
 }
 
 func (h *HLSFilter) normalizeVariant(v *m3u8.Variant, absolute url.URL) (*m3u8.Variant, error) {
@@ -378,7 +379,8 @@ func (h *HLSFilter) normalizeTrimmedVariant(filters *parsers.MediaFilters, uri s
 }
 
 func combinedIfRelative(uri string, absolute url.URL) (string, error) {
-	if len(uri) == 0 {
+	// without a base there is nothing to resolve the uri against
+	if len(uri) == 0 || absolute == (url.URL{}) {
 		return uri, nil
 	}
 	relative, err := isRelative(uri)
@@ -457,6 +459,7 @@ func (h *HLSFilter) trimRenditionManifest(filters *parsers.MediaFilters, m *m3u8
 
 	h.maxSegmentSize = maxSize
 	filteredPlaylist.Close()
+	runMediaPlugins(filters.Plugins, filteredPlaylist)
 
 	return isEmpty(filteredPlaylist.Encode().String())
 }
@@ -542,12 +545,7 @@ func isValidPipeline(pipeline pipelineType, index int) bool {
 
 //Health check variant of redundant manifest
 func healthCheckVariant(ctx context.Context, variantURL string, client config.Client) (bool, error) {
-	o, err := origin.NewDefaultOrigin("", variantURL)
-	if err != nil {
-		return false, fmt.Errorf("health checking variant: %w", err)
-	}
-
-	manifestInfo, err := o.FetchOriginContent(ctx, client)
+	manifestInfo, err := fetchVariant(ctx, variantURL, client)
 	if err != nil {
 		return false, fmt.Errorf("health checking variant: %w", err)
 	}
@@ -567,6 +565,17 @@ func healthCheckVariant(ctx context.Context, variantURL string, client config.Cl
 	return evaluateStaleness(manifestInfo.Payload, manifestInfo.LastModified)
 }
 
+// fetchVariant fetches a variant playlist of the master playlist from its
+// absolute URL
+func fetchVariant(ctx context.Context, variantURL string, client config.Client) (origin.OriginContentInfo, error) {
+	o, err := origin.NewDefaultOrigin("", variantURL)
+	if err != nil {
+		return origin.OriginContentInfo{}, err
+	}
+
+	return o.FetchOriginContent(ctx, client)
+}
+
 func evaluateStaleness(variant string, lastModified time.Time) (bool, error) {
 	v, _, err := m3u8.DecodeFrom(strings.NewReader(variant), true)
 	if err != nil {
@@ -1175,6 +1175,21 @@ link_1.m3u8
 http://existing.base/uri/nested/folders/link_2.m3u8
 #EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=4000,AVERAGE-BANDWIDTH=4000,AUDIO="AU2",CLOSED-CAPTIONS="CC"
 ../../link_3.m3u8
+`
+
+	// the AU2 audio group is not defined, so variants referencing it have their
+	// AUDIO attribute cleared
+	manifestWithAbsoluteOnlyExpected := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="AU",NAME="ENGLISH",DEFAULT=NO,LANGUAGE="ENG",URI="http://existing.base/uri/nested/folders/audio.m3u8"
+#EXT-X-MEDIA:TYPE=VIDEO,GROUP-ID="VID",NAME="ENGLISH",DEFAULT=NO,LANGUAGE="ENG",URI="http://existing.base/uri/nested/folders/video.m3u8"
+#EXT-X-MEDIA:TYPE=CLOSED-CAPTIONS,GROUP-ID="CC",NAME="ENGLISH",DEFAULT=NO,LANGUAGE="ENG"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,AVERAGE-BANDWIDTH=1000,AUDIO="AU",VIDEO="VID",CLOSED-CAPTIONS="CC"
+http://existing.base/uri/nested/folders/link_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=4000,AVERAGE-BANDWIDTH=4000,CLOSED-CAPTIONS="CC"
+http://existing.base/uri/nested/folders/link_2.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=4000,AVERAGE-BANDWIDTH=4000,CLOSED-CAPTIONS="CC"
+http://existing.base/uri/link_3.m3u8
 `
 
 	manifestWithDifferentAbsolute := `#EXTM3U
@@ -1223,22 +1238,22 @@ http://existi\ng.base/uri/link_1.m3u8
 		expectErr             bool
 	}{
 		{
-			name:                  "when manifest contains only absolute uris, expect same manifest",
+			name:                  "when manifest contains only absolute uris, expect same manifest with undefined groups cleared",
 			filters:               &parsers.MediaFilters{},
 			manifestContent:       manifestWithAbsoluteOnly,
-			expectManifestContent: manifestWithAbsoluteOnly,
+			expectManifestContent: manifestWithAbsoluteOnlyExpected,
 		},
 		{
 			name:                  "when manifest contains only relative urls, expect all urls to become absolute",
 			filters:               &parsers.MediaFilters{},
 			manifestContent:       manifestWithRelativeOnly,
-			expectManifestContent: manifestWithAbsoluteOnly,
+			expectManifestContent: manifestWithAbsoluteOnlyExpected,
 		},
 		{
 			name:                  "when manifest contains both absolute and relative urls, expect all urls to be absolute",
 			filters:               &parsers.MediaFilters{},
 			manifestContent:       manifestWithRelativeAndAbsolute,
-			expectManifestContent: manifestWithAbsoluteOnly,
+			expectManifestContent: manifestWithAbsoluteOnlyExpected,
 		},
 		{
 			name:                  "when manifest contains relative urls and absolute urls (with different base url), expect only relative urls to be changes to have base url as base",
@@ -2417,7 +2432,7 @@ https://existing.base/path/index-f7-v1.m3u8
 
 	masterManifestWithSpanishAndPortugeseAndNoSubtitles := `#EXTM3U
 #EXT-X-VERSION:4
-#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio0",NAME="Spanish (Latin America)",DEFAULT=NO,AUTOSELECT=NO,LANGUAGE="es",URI="https://existing.base/path/index-f12-a1.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio0",NAME="Spanish (Latin America)",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="es",URI="https://existing.base/path/index-f12-a1.m3u8"
 #EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio0",NAME="Brazilian Portuguese",DEFAULT=NO,AUTOSELECT=NO,LANGUAGE="pt",URI="https://existing.base/path/index-f16-a1.m3u8"
 #EXT-X-STREAM-INF:PROGRAM-ID=1,BANDWIDTH=277965,CODECS="avc1.4d401e,mp4a.40.2",RESOLUTION=384x216,AUDIO="audio0",FRAME-RATE=25.000,VIDEO-RANGE=SDR
 https://existing.base/path/index-f1-v1.m3u8
@@ -2684,6 +2699,15 @@ https://existing.base/path/link_6.m3u8
 https://existing.base/path/link_4.m3u8
 #EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=4100,AVERAGE-BANDWIDTH=4100,CODECS="avc1.64001f,mp4a.40.2",CLOSED-CAPTIONS="CC",FRAME-RATE=59.940
 https://existing.base/path/link_5.m3u8
+`
+
+	masterManifestWithOnly30FrameRate := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=CLOSED-CAPTIONS,GROUP-ID="CC",NAME="ENGLISH",DEFAULT=NO,LANGUAGE="ENG"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,AVERAGE-BANDWIDTH=1000,CODECS="avc1.64001f,mp4a.40.2",CLOSED-CAPTIONS="CC",FRAME-RATE=30.000
+https://existing.base/path/link_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=4200,AVERAGE-BANDWIDTH=4200,CODECS="avc1.64001f,mp4a.40.2",CLOSED-CAPTIONS="CC",FRAME-RATE=30.000
+https://existing.base/path/link_2.m3u8
 `
 
 	masterManifestWithNoVariants := `#EXTM3U
@@ -2703,6 +2727,30 @@ https://existing.base/path/link_5.m3u8
 			manifestContent:       masterManifestWithMultipleFrameRates,
 			expectManifestContent: masterManifestWithMultipleFrameRates,
 		},
+		{
+			name: "when framerate is set as a fraction, expect the matching decimal variants removed",
+			filters: &parsers.MediaFilters{
+				FrameRate: []string{"60000/1001"},
+			},
+			manifestContent:       masterManifestWithMultipleFrameRates,
+			expectManifestContent: masterManifestWithout5994FrameRate,
+		},
+		{
+			name: "when framerate is set without trailing zeros, expect the matching variants removed",
+			filters: &parsers.MediaFilters{
+				FrameRate: []string{"59.94"},
+			},
+			manifestContent:       masterManifestWithMultipleFrameRates,
+			expectManifestContent: masterManifestWithout5994FrameRate,
+		},
+		{
+			name: "when a framerate range up to 30 is set, expect higher framerates removed",
+			filters: &parsers.MediaFilters{
+				FrameRateRange: &parsers.FrameRateRange{Max: 30},
+			},
+			manifestContent:       masterManifestWithMultipleFrameRates,
+			expectManifestContent: masterManifestWithOnly30FrameRate,
+		},
 		{
 			name: "when framerate is set to 60, variant of 60fps is removed",
 			filters: &parsers.MediaFilters{
@@ -2751,6 +2799,544 @@ https://existing.base/path/link_5.m3u8
 	}
 }
 
+func TestHLSFilter_FilterContent_Expression(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish",DEFAULT=NO,LANGUAGE="es",URI="http://existing.base/uri/audio_es.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000000,CODECS="hvc1.2.4.L123.B0,mp4a.40.2",RESOLUTION=1920x1080,FRAME-RATE=29.970,AUDIO="aac"
+http://existing.base/uri/link_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=6000000,CODECS="hvc1.2.4.L150.B0,mp4a.40.2",RESOLUTION=3840x2160,FRAME-RATE=60.000,AUDIO="aac"
+http://existing.base/uri/link_2.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=9000000,CODECS="hvc1.2.4.L150.B0,mp4a.40.2",RESOLUTION=3840x2160,FRAME-RATE=29.970,AUDIO="aac"
+http://existing.base/uri/link_3.m3u8
+`
+
+	tests := []struct {
+		name                  string
+		expressions           []string
+		expectManifestContent string
+	}{
+		{
+			name:        "when hevc above 1080p is removed unless 60fps, expect the 30fps 2160p variant removed",
+			expressions: []string{`codecs~"hvc" && height>1080 && !(fps==60)`},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish",DEFAULT=NO,LANGUAGE="es",URI="http://existing.base/uri/audio_es.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000000,CODECS="hvc1.2.4.L123.B0,mp4a.40.2",RESOLUTION=1920x1080,AUDIO="aac",FRAME-RATE=29.970
+http://existing.base/uri/link_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=6000000,CODECS="hvc1.2.4.L150.B0,mp4a.40.2",RESOLUTION=3840x2160,AUDIO="aac",FRAME-RATE=60.000
+http://existing.base/uri/link_2.m3u8
+`,
+		},
+		{
+			name:        "when several expressions are set, expect renditions matching any of them removed",
+			expressions: []string{`bandwidth>=6000000`, `lang=="es"`},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000000,CODECS="hvc1.2.4.L123.B0,mp4a.40.2",RESOLUTION=1920x1080,AUDIO="aac",FRAME-RATE=29.970
+http://existing.base/uri/link_1.m3u8
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			filters := &parsers.MediaFilters{}
+			for _, source := range tt.expressions {
+				e, err := parsers.ParseExpression(source)
+				if err != nil {
+					t.Fatalf("ParseExpression() didnt expect an error to be returned, got: %v", err)
+				}
+				filters.Expressions = append(filters.Expressions, e)
+			}
+
+			filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+			got, err := filter.FilterContent(context.Background(), filters)
+			if err != nil {
+				t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+			}
+
+			if g, e := got, tt.expectManifestContent; g != e {
+				t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+					cmp.Diff(g, e))
+			}
+		})
+	}
+}
+
+func TestHLSFilter_FilterContent_VideoRange(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2"
+http://existing.base/uri/sdr.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="hvc1.2.4.L153.B0,mp4a.40.2",VIDEO-RANGE=PQ
+http://existing.base/uri/hdr10.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=3000,CODECS="hvc1.2.4.L153.B0,mp4a.40.2",VIDEO-RANGE=HLG
+http://existing.base/uri/hlg.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=4000,CODECS="dvh1.05.06,ec-3"
+http://existing.base/uri/dolby.m3u8
+`
+
+	tests := []struct {
+		name                  string
+		filters               *parsers.MediaFilters
+		expectManifestContent string
+	}{
+		{
+			name:    "when pq is filtered, expect hdr10 and dolby vision variants removed",
+			filters: &parsers.MediaFilters{VideoRanges: []string{"pq"}},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2"
+http://existing.base/uri/sdr.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=3000,CODECS="hvc1.2.4.L153.B0,mp4a.40.2",VIDEO-RANGE=HLG
+http://existing.base/uri/hlg.m3u8
+`,
+		},
+		{
+			name:    "when sdr and hlg are filtered, expect only pq variants kept",
+			filters: &parsers.MediaFilters{VideoRanges: []string{"sdr", "hlg"}},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="hvc1.2.4.L153.B0,mp4a.40.2",VIDEO-RANGE=PQ
+http://existing.base/uri/hdr10.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=4000,CODECS="dvh1.05.06,ec-3"
+http://existing.base/uri/dolby.m3u8
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+			got, err := filter.FilterContent(context.Background(), tt.filters)
+			if err != nil {
+				t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+			}
+
+			if g, e := got, tt.expectManifestContent; g != e {
+				t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+					cmp.Diff(g, e))
+			}
+		})
+	}
+}
+
+func TestHLSFilter_FilterContent_Channels(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="stereo",NAME="English",DEFAULT=YES,LANGUAGE="en",CHANNELS="2",URI="http://existing.base/uri/stereo.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="surround",NAME="English",DEFAULT=YES,LANGUAGE="en",CHANNELS="6",URI="http://existing.base/uri/surround.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="surround",NAME="English Stereo",DEFAULT=NO,LANGUAGE="en",CHANNELS="2",URI="http://existing.base/uri/surround_stereo.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="atmos",NAME="English",DEFAULT=YES,LANGUAGE="en",CHANNELS="16/JOC",URI="http://existing.base/uri/atmos.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="stereo"
+http://existing.base/uri/link_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.640029,ac-3",AUDIO="surround"
+http://existing.base/uri/link_2.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=3000,CODECS="avc1.640029,ec-3",AUDIO="atmos"
+http://existing.base/uri/link_3.m3u8
+`
+
+	tests := []struct {
+		name                  string
+		filters               *parsers.MediaFilters
+		expectManifestContent string
+	}{
+		{
+			name:    "when atmos is above the maximum, expect the variant referencing only atmos removed",
+			filters: &parsers.MediaFilters{Audios: parsers.NestedFilters{MaxChannels: 6}},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="stereo",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/stereo.m3u8",CHANNELS="2"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="stereo"
+http://existing.base/uri/link_1.m3u8
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="surround",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/surround.m3u8",CHANNELS="6"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="surround",NAME="English Stereo",DEFAULT=NO,LANGUAGE="en",URI="http://existing.base/uri/surround_stereo.m3u8",CHANNELS="2"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.640029,ac-3",AUDIO="surround"
+http://existing.base/uri/link_2.m3u8
+`,
+		},
+		{
+			name:    "when stereo is the maximum, expect surround alternatives removed, a new default promoted and the surround variant kept",
+			filters: &parsers.MediaFilters{Audios: parsers.NestedFilters{MaxChannels: 2}},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="stereo",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/stereo.m3u8",CHANNELS="2"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="stereo"
+http://existing.base/uri/link_1.m3u8
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="surround",NAME="English Stereo",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="en",URI="http://existing.base/uri/surround_stereo.m3u8",CHANNELS="2"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.640029,ac-3",AUDIO="surround"
+http://existing.base/uri/link_2.m3u8
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+			got, err := filter.FilterContent(context.Background(), tt.filters)
+			if err != nil {
+				t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+			}
+
+			if g, e := got, tt.expectManifestContent; g != e {
+				t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+					cmp.Diff(g, e))
+			}
+		})
+	}
+}
+
+func TestHLSFilter_FilterContent_CodecProfile(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.4d401f,mp4a.40.2"
+http://existing.base/uri/avc_main.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.640033,mp4a.40.2"
+http://existing.base/uri/avc_high.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=3000,CODECS="hvc1.1.6.L120.90,mp4a.40.2"
+http://existing.base/uri/hevc_main.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=4000,CODECS="hev1.2.4.L153.B0,mp4a.40.2"
+http://existing.base/uri/hevc_main10.m3u8
+`
+
+	expect := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.4d401f,mp4a.40.2"
+http://existing.base/uri/avc_main.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=3000,CODECS="hvc1.1.6.L120.90,mp4a.40.2"
+http://existing.base/uri/hevc_main.m3u8
+`
+
+	filters := &parsers.MediaFilters{Videos: parsers.NestedFilters{CodecConstraints: []parsers.CodecConstraint{
+		{Family: parsers.CodecFamilyAVC, Conditions: []parsers.CodecCondition{{Attribute: "level", Operator: "<=", Value: "4.1"}}},
+		{Family: parsers.CodecFamilyHEVC, Conditions: []parsers.CodecCondition{{Attribute: "profile", Operator: "==", Value: "main"}}},
+	}}}
+
+	filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), filters)
+	if err != nil {
+		t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+			cmp.Diff(g, e))
+	}
+}
+
+func TestHLSFilter_FilterContent_LanguageTags(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,LANGUAGE="en-US",URI="http://existing.base/uri/audio_en_us.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="British",DEFAULT=NO,LANGUAGE="en-GB",URI="http://existing.base/uri/audio_en_gb.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish",DEFAULT=NO,LANGUAGE="spa",URI="http://existing.base/uri/audio_es.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English",DEFAULT=NO,LANGUAGE="ENG",URI="http://existing.base/uri/subs_en.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="aac",SUBTITLES="subs"
+http://existing.base/uri/link_1.m3u8
+`
+
+	expect := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="spa",URI="http://existing.base/uri/audio_es.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="aac"
+http://existing.base/uri/link_1.m3u8
+`
+
+	filters := &parsers.MediaFilters{
+		Audios:   parsers.NestedFilters{Language: []string{"en"}},
+		Captions: parsers.NestedFilters{Language: []string{"en"}},
+	}
+
+	filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), filters)
+	if err != nil {
+		t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+			cmp.Diff(g, e))
+	}
+}
+
+func TestHLSFilter_FilterContent_Defaults(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish",DEFAULT=NO,AUTOSELECT=NO,LANGUAGE="es-419",URI="http://existing.base/uri/audio_es.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="Spanish",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="es",URI="http://existing.base/uri/subs_es.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English",DEFAULT=NO,AUTOSELECT=NO,LANGUAGE="en",URI="http://existing.base/uri/subs_en.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="aac",SUBTITLES="subs"
+http://existing.base/uri/link_1.m3u8
+`
+
+	tests := []struct {
+		name                  string
+		filters               *parsers.MediaFilters
+		expectManifestContent string
+	}{
+		{
+			name:    "when defaults are set, expect the matching alternatives made default and the others cleared",
+			filters: &parsers.MediaFilters{Defaults: &parsers.Defaults{Audio: []string{"es"}, Captions: []string{"en"}}},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=NO,AUTOSELECT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="es-419",URI="http://existing.base/uri/audio_es.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="Spanish",DEFAULT=NO,AUTOSELECT=YES,LANGUAGE="es",URI="http://existing.base/uri/subs_es.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="en",URI="http://existing.base/uri/subs_en.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="aac",SUBTITLES="subs"
+http://existing.base/uri/link_1.m3u8
+`,
+		},
+		{
+			name:    "when no alternative matches the default languages, expect the defaults untouched",
+			filters: &parsers.MediaFilters{Defaults: &parsers.Defaults{Audio: []string{"fr"}}},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish",DEFAULT=NO,AUTOSELECT=NO,LANGUAGE="es-419",URI="http://existing.base/uri/audio_es.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="Spanish",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="es",URI="http://existing.base/uri/subs_es.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English",DEFAULT=NO,AUTOSELECT=NO,LANGUAGE="en",URI="http://existing.base/uri/subs_en.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="aac",SUBTITLES="subs"
+http://existing.base/uri/link_1.m3u8
+`,
+		},
+		{
+			name:    "when the default alternative is filtered out, expect the first remaining one promoted",
+			filters: &parsers.MediaFilters{Audios: parsers.NestedFilters{Language: []string{"en"}}},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="es-419",URI="http://existing.base/uri/audio_es.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="Spanish",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="es",URI="http://existing.base/uri/subs_es.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English",DEFAULT=NO,AUTOSELECT=NO,LANGUAGE="en",URI="http://existing.base/uri/subs_en.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="aac",SUBTITLES="subs"
+http://existing.base/uri/link_1.m3u8
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+			got, err := filter.FilterContent(context.Background(), tt.filters)
+			if err != nil {
+				t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+			}
+
+			if g, e := got, tt.expectManifestContent; g != e {
+				t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+					cmp.Diff(g, e))
+			}
+		})
+	}
+}
+
+func TestHLSFilter_FilterContent_DefaultsRoles(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English (AD)",DEFAULT=NO,AUTOSELECT=NO,LANGUAGE="en",CHARACTERISTICS="public.accessibility.describes-video",URI="http://existing.base/uri/audio_en_ad.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=NO,AUTOSELECT=NO,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="es",URI="http://existing.base/uri/audio_es.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="aac"
+http://existing.base/uri/link_1.m3u8
+`
+
+	expect := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English (AD)",DEFAULT=NO,AUTOSELECT=NO,LANGUAGE="en",CHARACTERISTICS="public.accessibility.describes-video",URI="http://existing.base/uri/audio_en_ad.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish",DEFAULT=NO,AUTOSELECT=YES,LANGUAGE="es",URI="http://existing.base/uri/audio_es.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="aac"
+http://existing.base/uri/link_1.m3u8
+`
+
+	filters := &parsers.MediaFilters{Defaults: &parsers.Defaults{Audio: []string{"en"}}}
+
+	filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), filters)
+	if err != nil {
+		t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+			cmp.Diff(g, e))
+	}
+}
+
+func TestHLSFilter_FilterContent_DefaultsFallbackRoles(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish (AD)",DEFAULT=NO,AUTOSELECT=NO,LANGUAGE="es",CHARACTERISTICS="public.accessibility.describes-video",URI="http://existing.base/uri/audio_es_ad.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish",DEFAULT=NO,AUTOSELECT=NO,LANGUAGE="es",URI="http://existing.base/uri/audio_es.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="aac"
+http://existing.base/uri/link_1.m3u8
+`
+
+	expect := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish (AD)",DEFAULT=NO,AUTOSELECT=NO,LANGUAGE="es",CHARACTERISTICS="public.accessibility.describes-video",URI="http://existing.base/uri/audio_es_ad.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Spanish",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="es",URI="http://existing.base/uri/audio_es.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="aac"
+http://existing.base/uri/link_1.m3u8
+`
+
+	filters := &parsers.MediaFilters{Audios: parsers.NestedFilters{Language: []string{"en"}}}
+
+	filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), filters)
+	if err != nil {
+		t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+			cmp.Diff(g, e))
+	}
+}
+
+func TestHLSFilter_FilterContent_CaptionOptions(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English",DEFAULT=NO,LANGUAGE="en",URI="http://existing.base/uri/subs_en.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English Forced",DEFAULT=NO,LANGUAGE="en",FORCED="YES",URI="http://existing.base/uri/subs_en_forced.m3u8"
+#EXT-X-MEDIA:TYPE=CLOSED-CAPTIONS,GROUP-ID="cc",NAME="English",DEFAULT=NO,LANGUAGE="en",INSTREAM-ID="CC1"
+#EXT-X-MEDIA:TYPE=CLOSED-CAPTIONS,GROUP-ID="cc",NAME="Spanish",DEFAULT=NO,LANGUAGE="es",INSTREAM-ID="SERVICE2"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",SUBTITLES="subs",CLOSED-CAPTIONS="cc"
+http://existing.base/uri/link_1.m3u8
+`
+
+	tests := []struct {
+		name                  string
+		filters               *parsers.MediaFilters
+		expectManifestContent string
+	}{
+		{
+			name:    "when forced subtitles are dropped, expect forced subtitles removed and closed captions kept",
+			filters: &parsers.MediaFilters{Captions: parsers.NestedFilters{Forced: parsers.ForcedDrop}},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English",DEFAULT=NO,LANGUAGE="en",URI="http://existing.base/uri/subs_en.m3u8"
+#EXT-X-MEDIA:TYPE=CLOSED-CAPTIONS,GROUP-ID="cc",NAME="English",DEFAULT=NO,LANGUAGE="en",INSTREAM-ID="CC1"
+#EXT-X-MEDIA:TYPE=CLOSED-CAPTIONS,GROUP-ID="cc",NAME="Spanish",DEFAULT=NO,LANGUAGE="es",INSTREAM-ID="SERVICE2"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",CLOSED-CAPTIONS="cc",SUBTITLES="subs"
+http://existing.base/uri/link_1.m3u8
+`,
+		},
+		{
+			name:    "when only forced subtitles are kept, expect other subtitles removed",
+			filters: &parsers.MediaFilters{Captions: parsers.NestedFilters{Forced: parsers.ForcedOnly}},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English Forced",DEFAULT=NO,LANGUAGE="en",FORCED="YES",URI="http://existing.base/uri/subs_en_forced.m3u8"
+#EXT-X-MEDIA:TYPE=CLOSED-CAPTIONS,GROUP-ID="cc",NAME="English",DEFAULT=NO,LANGUAGE="en",INSTREAM-ID="CC1"
+#EXT-X-MEDIA:TYPE=CLOSED-CAPTIONS,GROUP-ID="cc",NAME="Spanish",DEFAULT=NO,LANGUAGE="es",INSTREAM-ID="SERVICE2"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",CLOSED-CAPTIONS="cc",SUBTITLES="subs"
+http://existing.base/uri/link_1.m3u8
+`,
+		},
+		{
+			name:    "when instream ids are filtered, expect the matching closed captions removed",
+			filters: &parsers.MediaFilters{Captions: parsers.NestedFilters{InstreamIDs: []string{"SERVICE2"}}},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English",DEFAULT=NO,LANGUAGE="en",URI="http://existing.base/uri/subs_en.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English Forced",DEFAULT=NO,LANGUAGE="en",FORCED="YES",URI="http://existing.base/uri/subs_en_forced.m3u8"
+#EXT-X-MEDIA:TYPE=CLOSED-CAPTIONS,GROUP-ID="cc",NAME="English",DEFAULT=NO,LANGUAGE="en",INSTREAM-ID="CC1"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",CLOSED-CAPTIONS="cc",SUBTITLES="subs"
+http://existing.base/uri/link_1.m3u8
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+			got, err := filter.FilterContent(context.Background(), tt.filters)
+			if err != nil {
+				t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+			}
+
+			if g, e := got, tt.expectManifestContent; g != e {
+				t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+					cmp.Diff(g, e))
+			}
+		})
+	}
+}
+
+func TestHLSFilter_FilterContent_Roles(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English AD",DEFAULT=NO,LANGUAGE="en",CHARACTERISTICS="public.accessibility.describes-video",URI="http://existing.base/uri/audio_en_ad.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English",DEFAULT=NO,LANGUAGE="en",URI="http://existing.base/uri/subs_en.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English SDH",DEFAULT=NO,LANGUAGE="en",CHARACTERISTICS="public.accessibility.transcribes-spoken-dialog,public.accessibility.describes-music-and-sound",URI="http://existing.base/uri/subs_en_sdh.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="aac",SUBTITLES="subs"
+http://existing.base/uri/link_1.m3u8
+`
+
+	tests := []struct {
+		name                  string
+		filters               *parsers.MediaFilters
+		expectManifestContent string
+	}{
+		{
+			name: "when audio description is filtered, expect described video alternatives removed",
+			filters: &parsers.MediaFilters{
+				Audios:   parsers.NestedFilters{Roles: []string{"description"}},
+				Captions: parsers.NestedFilters{Roles: []string{"description"}},
+			},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English",DEFAULT=NO,LANGUAGE="en",URI="http://existing.base/uri/subs_en.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English SDH",DEFAULT=NO,LANGUAGE="en",CHARACTERISTICS="public.accessibility.transcribes-spoken-dialog,public.accessibility.describes-music-and-sound",URI="http://existing.base/uri/subs_en_sdh.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="aac",SUBTITLES="subs"
+http://existing.base/uri/link_1.m3u8
+`,
+		},
+		{
+			name:    "when captions are filtered, expect subtitles transcribing dialog removed",
+			filters: &parsers.MediaFilters{Captions: parsers.NestedFilters{Roles: []string{"caption"}}},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,AUTOSELECT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English AD",DEFAULT=NO,LANGUAGE="en",CHARACTERISTICS="public.accessibility.describes-video",URI="http://existing.base/uri/audio_en_ad.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English",DEFAULT=NO,LANGUAGE="en",URI="http://existing.base/uri/subs_en.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.640029,mp4a.40.2",AUDIO="aac",SUBTITLES="subs"
+http://existing.base/uri/link_1.m3u8
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+			got, err := filter.FilterContent(context.Background(), tt.filters)
+			if err != nil {
+				t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+			}
+
+			if g, e := got, tt.expectManifestContent; g != e {
+				t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+					cmp.Diff(g, e))
+			}
+		})
+	}
+}
+
 func TestHLSFilter_FilterContent_RedundantManifests(t *testing.T) {
 	redundant := `#EXTM3U
 #EXT-X-VERSION:4
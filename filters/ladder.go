@@ -0,0 +1,152 @@
+package filters
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/grafov/m3u8"
+	"github.com/zencoder/go-dash/mpd"
+)
+
+// shapeLadder returns the indexes of the renditions kept by the ladder filter,
+// each rendition being a rung of the ladder ordered by bandwidth
+func shapeLadder(l *parsers.Ladder, bandwidths []int) map[int]struct{} {
+	rungs := make([]int, len(bandwidths))
+	for i := range rungs {
+		rungs[i] = i
+	}
+	sort.SliceStable(rungs, func(i, j int) bool {
+		return bandwidths[rungs[i]] < bandwidths[rungs[j]]
+	})
+
+	// keep the highest rung and walk down, skipping rungs too close to the last kept
+	if l.MinRatio > 0 && len(rungs) > 1 {
+		spaced := []int{rungs[len(rungs)-1]}
+		for i := len(rungs) - 2; i >= 0; i-- {
+			if float64(bandwidths[spaced[0]]) >= float64(bandwidths[rungs[i]])*l.MinRatio {
+				spaced = append([]int{rungs[i]}, spaced...)
+			}
+		}
+		rungs = spaced
+	}
+
+	if l.Max > 0 && len(rungs) > l.Max {
+		switch l.Keep {
+		case parsers.LadderKeepLowest:
+			rungs = rungs[:l.Max]
+		case parsers.LadderKeepEven:
+			rungs = evenRungs(rungs, l.Max)
+		default:
+			rungs = rungs[len(rungs)-l.Max:]
+		}
+	}
+
+	kept := make(map[int]struct{}, len(rungs))
+	for _, i := range rungs {
+		kept[i] = struct{}{}
+	}
+
+	return kept
+}
+
+// evenRungs returns n rungs evenly spread from the lowest to the highest one
+func evenRungs(rungs []int, n int) []int {
+	if n == 1 {
+		return rungs[len(rungs)-1:]
+	}
+
+	spread := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		index := int(math.Round(float64(i*(len(rungs)-1)) / float64(n-1)))
+		spread = append(spread, rungs[index])
+	}
+
+	return spread
+}
+
+// ladderVariants returns the variants removed by the ladder filter. The video
+// variants of the master form a single ladder across codecs, while I-Frame and
+// audio only variants are left untouched
+func ladderVariants(l *parsers.Ladder, variants []*m3u8.Variant) map[*m3u8.Variant]struct{} {
+	if l == nil {
+		return nil
+	}
+
+	var ladder []*m3u8.Variant
+	var bandwidths []int
+	for _, v := range variants {
+		if v.Iframe {
+			continue
+		}
+
+		if len(newVariantRendition(v).codecs(videoContentType)) == 0 && v.Resolution == "" {
+			continue
+		}
+
+		ladder = append(ladder, v)
+		bandwidths = append(bandwidths, int(v.Bandwidth))
+	}
+
+	kept := shapeLadder(l, bandwidths)
+	removed := map[*m3u8.Variant]struct{}{}
+	for i, v := range ladder {
+		if _, found := kept[i]; !found {
+			removed[v] = struct{}{}
+		}
+	}
+
+	return removed
+}
+
+// ladderAdaptationSets applies the ladder filter to the video Representations
+// of each AdaptationSet
+func ladderAdaptationSets(l *parsers.Ladder, manifest *mpd.MPD) {
+	if l == nil {
+		return
+	}
+
+	for _, period := range manifest.Periods {
+		for _, as := range period.AdaptationSets {
+			var bandwidths []int
+			for _, r := range as.Representations {
+				if isVideoRepresentation(as, r) {
+					bandwidths = append(bandwidths, int(*r.Bandwidth))
+				}
+			}
+
+			if len(bandwidths) == 0 {
+				continue
+			}
+
+			kept := shapeLadder(l, bandwidths)
+			var filteredReps []*mpd.Representation
+			rung := 0
+			for _, r := range as.Representations {
+				if isVideoRepresentation(as, r) {
+					_, found := kept[rung]
+					rung++
+					if !found {
+						continue
+					}
+				}
+				filteredReps = append(filteredReps, r)
+			}
+			as.Representations = filteredReps
+		}
+	}
+}
+
+func isVideoRepresentation(as *mpd.AdaptationSet, r *mpd.Representation) bool {
+	if r.Bandwidth == nil {
+		return false
+	}
+
+	for _, ct := range newRepresentationRendition(as, r).contentTypes() {
+		if ct == videoContentType {
+			return true
+		}
+	}
+
+	return false
+}
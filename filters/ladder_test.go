@@ -0,0 +1,232 @@
+package filters
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/cbsinteractive/bakery/config"
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestShapeLadder(t *testing.T) {
+	ladder := []int{400, 800, 1200, 1600, 2400, 3200, 4800, 6400}
+
+	tests := []struct {
+		name       string
+		ladder     *parsers.Ladder
+		bandwidths []int
+		expect     []int
+	}{
+		{
+			name:       "when keeping the highest rungs, expect the top n kept",
+			ladder:     &parsers.Ladder{Max: 3, Keep: parsers.LadderKeepHighest},
+			bandwidths: ladder,
+			expect:     []int{3200, 4800, 6400},
+		},
+		{
+			name:       "when keeping the lowest rungs, expect the bottom n kept",
+			ladder:     &parsers.Ladder{Max: 3, Keep: parsers.LadderKeepLowest},
+			bandwidths: ladder,
+			expect:     []int{400, 800, 1200},
+		},
+		{
+			name:       "when keeping evenly spread rungs, expect the lowest and highest kept",
+			ladder:     &parsers.Ladder{Max: 3, Keep: parsers.LadderKeepEven},
+			bandwidths: ladder,
+			expect:     []int{400, 2400, 6400},
+		},
+		{
+			name:       "when keeping a single evenly spread rung, expect the highest kept",
+			ladder:     &parsers.Ladder{Max: 1, Keep: parsers.LadderKeepEven},
+			bandwidths: ladder,
+			expect:     []int{6400},
+		},
+		{
+			name:       "when a minimum ratio is set, expect rungs too close to the one above removed",
+			ladder:     &parsers.Ladder{MinRatio: 1.9},
+			bandwidths: ladder,
+			expect:     []int{400, 800, 1600, 3200, 6400},
+		},
+		{
+			name:       "when a minimum ratio and a size are set, expect the ratio applied first",
+			ladder:     &parsers.Ladder{Max: 2, Keep: parsers.LadderKeepLowest, MinRatio: 1.9},
+			bandwidths: ladder,
+			expect:     []int{400, 800},
+		},
+		{
+			name:       "when renditions share a bandwidth, expect each counted as a rung",
+			ladder:     &parsers.Ladder{Max: 3, Keep: parsers.LadderKeepHighest},
+			bandwidths: []int{800, 800, 400, 400, 1200, 1200},
+			expect:     []int{800, 1200, 1200},
+		},
+		{
+			name:       "when the ladder is shorter than n, expect every rung kept",
+			ladder:     &parsers.Ladder{Max: 4},
+			bandwidths: []int{800, 400},
+			expect:     []int{400, 800},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for i := range shapeLadder(tt.ladder, tt.bandwidths) {
+				got = append(got, tt.bandwidths[i])
+			}
+			sort.Ints(got)
+
+			if !cmp.Equal(got, tt.expect) {
+				t.Errorf("Wrong rungs kept\ngot %v\nexpected: %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestHLSFilter_FilterContent_Ladder(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/avc_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/avc_2.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=3000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/avc_3.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1500,CODECS="hvc1.2.4.L93.90,mp4a.40.2"
+http://existing.base/uri/hevc_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2500,CODECS="hvc1.2.4.L93.90,mp4a.40.2"
+http://existing.base/uri/hevc_2.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=500,CODECS="mp4a.40.2"
+http://existing.base/uri/audio_only.m3u8
+#EXT-X-I-FRAME-STREAM-INF:BANDWIDTH=100,CODECS="avc1.77.30",URI="http://existing.base/uri/iframe.m3u8"
+`
+
+	tests := []struct {
+		name                  string
+		filters               *parsers.MediaFilters
+		expectManifestContent string
+	}{
+		{
+			name: "when the ladder is limited, expect the highest video variant of the master kept and other variants kept",
+			filters: &parsers.MediaFilters{
+				Ladder: &parsers.Ladder{Max: 1, Keep: parsers.LadderKeepHighest},
+			},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=3000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/avc_3.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=500,CODECS="mp4a.40.2"
+http://existing.base/uri/audio_only.m3u8
+#EXT-X-I-FRAME-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=100,CODECS="avc1.77.30",URI="http://existing.base/uri/iframe.m3u8"
+`,
+		},
+		{
+			name: "when the ladder is limited with several codecs, expect n video variants kept across codecs",
+			filters: &parsers.MediaFilters{
+				Ladder: &parsers.Ladder{Max: 2, Keep: parsers.LadderKeepHighest},
+			},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=3000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/avc_3.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2500,CODECS="hvc1.2.4.L93.90,mp4a.40.2"
+http://existing.base/uri/hevc_2.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=500,CODECS="mp4a.40.2"
+http://existing.base/uri/audio_only.m3u8
+#EXT-X-I-FRAME-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=100,CODECS="avc1.77.30",URI="http://existing.base/uri/iframe.m3u8"
+`,
+		},
+		{
+			name: "when other filters are set, expect the ladder shaped after them",
+			filters: &parsers.MediaFilters{
+				Videos: parsers.NestedFilters{Codecs: []string{"hvc"}},
+				Ladder: &parsers.Ladder{Max: 2, Keep: parsers.LadderKeepLowest},
+			},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/avc_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/avc_2.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=500,CODECS="mp4a.40.2"
+http://existing.base/uri/audio_only.m3u8
+#EXT-X-I-FRAME-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=100,CODECS="avc1.77.30",URI="http://existing.base/uri/iframe.m3u8"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+			got, err := filter.FilterContent(context.Background(), tt.filters)
+			if err != nil {
+				t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+			}
+
+			if g, e := got, tt.expectManifestContent; g != e {
+				t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e, cmp.Diff(g, e))
+			}
+		})
+	}
+}
+
+func TestDASHFilter_FilterContent_Ladder(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="video">
+      <Representation bandwidth="1000" codecs="avc1.77.30" id="0"></Representation>
+      <Representation bandwidth="1100" codecs="avc1.77.30" id="1"></Representation>
+      <Representation bandwidth="2000" codecs="avc1.77.30" id="2"></Representation>
+      <Representation bandwidth="4000" codecs="avc1.77.30" id="3"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="4"></Representation>
+      <Representation bandwidth="128" codecs="mp4a.40.2" id="5"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="video">
+      <Representation bandwidth="2000" codecs="avc1.77.30" id="2"></Representation>
+      <Representation bandwidth="4000" codecs="avc1.77.30" id="3"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="4"></Representation>
+      <Representation bandwidth="128" codecs="mp4a.40.2" id="5"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	filter := NewDASHFilter("", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), &parsers.MediaFilters{
+		Ladder: &parsers.Ladder{Max: 2, Keep: parsers.LadderKeepHighest, MinRatio: 1.5},
+	})
+	if err != nil {
+		t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", got, expect, cmp.Diff(got, expect))
+	}
+
+	var removedByLadder []string
+	for _, d := range filter.Decisions() {
+		if d.Filter == ladderFilter {
+			removedByLadder = append(removedByLadder, d.ID)
+		}
+	}
+
+	if e := []string{"0", "1"}; !cmp.Equal(removedByLadder, e) {
+		t.Errorf("Wrong representations attributed to the ladder filter\ngot %v\nexpected: %v", removedByLadder, e)
+	}
+}
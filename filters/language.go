@@ -0,0 +1,118 @@
+package filters
+
+import "strings"
+
+// iso6392Languages maps ISO 639-2 three letter codes, bibliographic and
+// terminologic, to their ISO 639-1 equivalent preferred by BCP-47
+var iso6392Languages = map[string]string{
+	"alb": "sq", "sqi": "sq",
+	"amh": "am",
+	"ara": "ar",
+	"arm": "hy", "hye": "hy",
+	"aze": "az",
+	"baq": "eu", "eus": "eu",
+	"bel": "be",
+	"ben": "bn",
+	"bos": "bs",
+	"bul": "bg",
+	"bur": "my", "mya": "my",
+	"cat": "ca",
+	"chi": "zh", "zho": "zh",
+	"cze": "cs", "ces": "cs",
+	"dan": "da",
+	"dut": "nl", "nld": "nl",
+	"eng": "en",
+	"est": "et",
+	"fil": "tl", "tgl": "tl",
+	"fin": "fi",
+	"fre": "fr", "fra": "fr",
+	"geo": "ka", "kat": "ka",
+	"ger": "de", "deu": "de",
+	"gle": "ga",
+	"glg": "gl",
+	"gre": "el", "ell": "el",
+	"guj": "gu",
+	"hat": "ht",
+	"heb": "he",
+	"hin": "hi",
+	"hrv": "hr",
+	"hun": "hu",
+	"ice": "is", "isl": "is",
+	"ind": "id",
+	"ita": "it",
+	"jpn": "ja",
+	"kan": "kn",
+	"kaz": "kk",
+	"khm": "km",
+	"kor": "ko",
+	"lao": "lo",
+	"lav": "lv",
+	"lit": "lt",
+	"mac": "mk", "mkd": "mk",
+	"mal": "ml",
+	"mao": "mi", "mri": "mi",
+	"mar": "mr",
+	"may": "ms", "msa": "ms",
+	"mon": "mn",
+	"nep": "ne",
+	"nor": "no", "nob": "nb", "nno": "nn",
+	"pan": "pa",
+	"per": "fa", "fas": "fa",
+	"pol": "pl",
+	"por": "pt",
+	"rum": "ro", "ron": "ro",
+	"rus": "ru",
+	"slo": "sk", "slk": "sk",
+	"slv": "sl",
+	"som": "so",
+	"spa": "es",
+	"srp": "sr",
+	"swa": "sw",
+	"swe": "sv",
+	"tam": "ta",
+	"tel": "te",
+	"tha": "th",
+	"tib": "bo", "bod": "bo",
+	"tur": "tr",
+	"ukr": "uk",
+	"urd": "ur",
+	"uzb": "uz",
+	"vie": "vi",
+	"wel": "cy", "cym": "cy",
+	"yid": "yi",
+	"zul": "zu",
+}
+
+// deprecatedLanguages maps deprecated ISO 639-1 codes to their replacement
+var deprecatedLanguages = map[string]string{
+	"iw": "he",
+	"in": "id",
+	"ji": "yi",
+}
+
+// canonicalLanguage returns the lower case BCP-47 form of a language tag, with
+// ISO 639-2 and deprecated primary subtags replaced by their ISO 639-1 code,
+// so that ENG, eng and en all return en, and en_US returns en-us
+func canonicalLanguage(tag string) string {
+	subtags := strings.Split(strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")), "-")
+	if code, found := iso6392Languages[subtags[0]]; found {
+		subtags[0] = code
+	} else if code, found := deprecatedLanguages[subtags[0]]; found {
+		subtags[0] = code
+	}
+
+	return strings.Join(subtags, "-")
+}
+
+// matchLanguage returns true if the language tag matches any of the given ones
+// once canonicalized, a tag matching its more specific tags, such as en with en-GB
+func matchLanguage(tag string, languages []string) bool {
+	lang := canonicalLanguage(tag)
+	for _, l := range languages {
+		if l := canonicalLanguage(l); lang == l || strings.HasPrefix(lang, l+"-") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,52 @@
+package filters
+
+import "testing"
+
+func TestCanonicalLanguage(t *testing.T) {
+	tests := []struct {
+		tag    string
+		expect string
+	}{
+		{tag: "en", expect: "en"},
+		{tag: "EN", expect: "en"},
+		{tag: "eng", expect: "en"},
+		{tag: "en_US", expect: "en-us"},
+		{tag: "fre-CA", expect: "fr-ca"},
+		{tag: "deu", expect: "de"},
+		{tag: "iw", expect: "he"},
+		{tag: "zh-Hant-TW", expect: "zh-hant-tw"},
+		{tag: "und", expect: "und"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := canonicalLanguage(tt.tag); got != tt.expect {
+				t.Errorf("canonicalLanguage() wrong tag returned\ngot %v\nexpected: %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestMatchLanguage(t *testing.T) {
+	tests := []struct {
+		name      string
+		tag       string
+		languages []string
+		expect    bool
+	}{
+		{name: "when the tag is a region of the language, expect a match", tag: "en-GB", languages: []string{"en"}, expect: true},
+		{name: "when the tag is a three letter code, expect a match", tag: "eng", languages: []string{"en"}, expect: true},
+		{name: "when the filter is a three letter code, expect a match", tag: "EN-us", languages: []string{"ENG"}, expect: true},
+		{name: "when the filter is more specific than the tag, expect no match", tag: "en", languages: []string{"en-GB"}},
+		{name: "when the tag only shares a prefix, expect no match", tag: "enm", languages: []string{"en"}},
+		{name: "when the language differs, expect no match", tag: "es-419", languages: []string{"en", "pt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchLanguage(tt.tag, tt.languages); got != tt.expect {
+				t.Errorf("matchLanguage() wrong result returned\ngot %v\nexpected: %v", got, tt.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package filters
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/grafov/m3u8"
+	"github.com/zencoder/go-dash/mpd"
+)
+
+// SeverityInfo flags issues which only matter to some filters, such as DeWeave
+const SeverityInfo Severity = "info"
+
+// pdtTolerance is the difference between the EXT-X-PROGRAM-DATE-TIME of a
+// segment and the end of the previous one under which there is no gap
+const pdtTolerance = 100 * time.Millisecond
+
+// LintManifest returns the issues found in an origin manifest, such as variants
+// missing CODECS or segments longer than the target duration, along with the
+// structural ones ValidateManifest reports. The base URL is the absolute URL the
+// manifest was fetched from, used to resolve relative URIs
+func LintManifest(protocol parsers.Protocol, baseURL, manifest string) []Issue {
+	issues := ValidateManifest(&parsers.MediaFilters{Protocol: protocol}, manifest)
+
+	switch protocol {
+	case parsers.ProtocolHLS:
+		return append(issues, lintHLS(baseURL, manifest)...)
+	case parsers.ProtocolDASH:
+		return append(issues, lintDASH(manifest)...)
+	}
+
+	return issues
+}
+
+func lintHLS(baseURL, manifest string) []Issue {
+	p, listType, err := m3u8.DecodeFrom(strings.NewReader(manifest), true)
+	if err != nil {
+		// already reported as a structural issue
+		return nil
+	}
+
+	base, _ := getAbsoluteURL(baseURL)
+	if listType == m3u8.MASTER {
+		return lintMasterPlaylist(base, p.(*m3u8.MasterPlaylist))
+	}
+
+	return lintMediaPlaylist(base, p.(*m3u8.MediaPlaylist), targetDuration(manifest))
+}
+
+// targetDuration returns the EXT-X-TARGETDURATION declared in the playlist, as
+// decoding raises it to the longest segment
+func targetDuration(manifest string) float64 {
+	var target float64
+	for _, line := range strings.Split(manifest, "\n") {
+		if strings.HasPrefix(line, "#EXT-X-TARGETDURATION:") {
+			fmt.Sscanf(line, "#EXT-X-TARGETDURATION:%f", &target)
+			break
+		}
+	}
+
+	return target
+}
+
+func lintMasterPlaylist(base *url.URL, playlist *m3u8.MasterPlaylist) []Issue {
+	var issues []Issue
+	var variants []*m3u8.Variant
+	for i, v := range playlist.Variants {
+		if v == nil {
+			continue
+		}
+
+		if !resolvable(base, v.URI) {
+			issues = append(issues, Issue{SeverityError, fmt.Sprintf("variant %d has an unresolvable URI %q", i, v.URI)})
+		}
+
+		if v.Iframe {
+			continue
+		}
+		variants = append(variants, v)
+
+		codecs := splitList(v.Codecs)
+		if len(codecs) == 0 {
+			issues = append(issues, Issue{SeverityWarning, fmt.Sprintf("variant %d has no CODECS", i)})
+		}
+
+		if v.Resolution == "" && len(newVariantRendition(v).codecs(videoContentType)) > 0 {
+			issues = append(issues, Issue{SeverityWarning, fmt.Sprintf("variant %d carries video without RESOLUTION", i)})
+		}
+	}
+
+	for _, alt := range uniqueAlternatives(playlist.Variants) {
+		if alt.URI != "" && !resolvable(base, alt.URI) {
+			issues = append(issues, Issue{SeverityError, fmt.Sprintf("%v alternative %q has an unresolvable URI %q", alt.Type, alt.Name, alt.URI)})
+		}
+	}
+
+	// only masters holding backup variants are meant for DeWeave
+	if hasBackupVariants(variants) && !redundantPairs(variants) {
+		issues = append(issues, Issue{SeverityInfo, "variants are not in redundant pairs, DeWeave drops the unpaired variants"})
+	}
+
+	return issues
+}
+
+// hasBackupVariants returns true if any two variants share their bandwidth and
+// resolution, as the primary and backup of a redundant pair do
+func hasBackupVariants(variants []*m3u8.Variant) bool {
+	type rung struct {
+		bandwidth  uint32
+		resolution string
+	}
+
+	seen := map[rung]bool{}
+	for _, v := range variants {
+		r := rung{v.Bandwidth, v.Resolution}
+		if seen[r] {
+			return true
+		}
+		seen[r] = true
+	}
+
+	return false
+}
+
+// redundantPairs returns true if the variants are interleaved as primary and
+// backup pairs advertising the same bandwidth, codecs and resolution, as
+// DeWeave expects
+func redundantPairs(variants []*m3u8.Variant) bool {
+	if len(variants)%2 != 0 {
+		return false
+	}
+
+	for i := 0; i < len(variants); i += 2 {
+		primary, backup := variants[i], variants[i+1]
+		if primary.Bandwidth != backup.Bandwidth || primary.Codecs != backup.Codecs || primary.Resolution != backup.Resolution {
+			return false
+		}
+	}
+
+	return true
+}
+
+func lintMediaPlaylist(base *url.URL, playlist *m3u8.MediaPlaylist, target float64) []Issue {
+	var issues []Issue
+	var previous *m3u8.MediaSegment
+	for i, segment := range playlist.Segments {
+		if segment == nil {
+			continue
+		}
+
+		if !resolvable(base, segment.URI) {
+			issues = append(issues, Issue{SeverityError, fmt.Sprintf("segment %d has an unresolvable URI %q", i, segment.URI)})
+		}
+
+		// EXTINF durations rounded to the nearest integer must not exceed it
+		if math.Round(segment.Duration) > target {
+			issues = append(issues, Issue{SeverityError, fmt.Sprintf("segment %d lasts %.3fs, more than the %vs EXT-X-TARGETDURATION", i, segment.Duration, target)})
+		}
+
+		if gap := pdtGap(previous, segment); gap != 0 {
+			issues = append(issues, Issue{SeverityWarning, fmt.Sprintf("segment %d EXT-X-PROGRAM-DATE-TIME is %v off the end of the previous segment", i, gap)})
+		}
+		previous = segment
+	}
+
+	return issues
+}
+
+// pdtGap returns the difference between the program date time of the segment
+// and the end of the previous one, 0 when within tolerance, across a
+// discontinuity or when either does not carry a program date time
+func pdtGap(previous, segment *m3u8.MediaSegment) time.Duration {
+	if previous == nil || previous.ProgramDateTime.IsZero() || segment.ProgramDateTime.IsZero() || segment.Discontinuity {
+		return 0
+	}
+
+	end := previous.ProgramDateTime.Add(time.Duration(previous.Duration * float64(time.Second)))
+	gap := segment.ProgramDateTime.Sub(end)
+	if gap > -pdtTolerance && gap < pdtTolerance {
+		return 0
+	}
+
+	return gap
+}
+
+// resolvable returns true if the URI, resolved against the base URL when
+// there is one, has a scheme and a host
+func resolvable(base *url.URL, uri string) bool {
+	if uri == "" {
+		return false
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+
+	return u.Scheme != "" && u.Host != ""
+}
+
+func lintDASH(manifest string) []Issue {
+	m, err := mpd.ReadFromString(manifest)
+	if err != nil {
+		// already reported as a structural issue
+		return nil
+	}
+
+	var issues []Issue
+	periods := map[string]struct{}{}
+	for i, period := range m.Periods {
+		if period.ID != "" {
+			if _, found := periods[period.ID]; found {
+				issues = append(issues, Issue{SeverityError, fmt.Sprintf("Period id %v is not unique", period.ID)})
+			}
+			periods[period.ID] = struct{}{}
+		}
+
+		adaptationSets := map[string]struct{}{}
+		representations := map[string]struct{}{}
+		for _, as := range period.AdaptationSets {
+			if id := strval(as.ID); id != "" {
+				if _, found := adaptationSets[id]; found {
+					issues = append(issues, Issue{SeverityError, fmt.Sprintf("AdaptationSet id %v is not unique in Period %v", id, periodID(period, i))})
+				}
+				adaptationSets[id] = struct{}{}
+			}
+
+			for _, rep := range as.Representations {
+				id := strval(rep.ID)
+				if id == "" {
+					issues = append(issues, Issue{SeverityError, fmt.Sprintf("Representation without id in Period %v", periodID(period, i))})
+					continue
+				}
+
+				if _, found := representations[id]; found {
+					issues = append(issues, Issue{SeverityError, fmt.Sprintf("Representation id %v is not unique in Period %v", id, periodID(period, i))})
+				}
+				representations[id] = struct{}{}
+			}
+		}
+	}
+
+	return issues
+}
@@ -0,0 +1,203 @@
+package filters
+
+import (
+	"testing"
+
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLintManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol parsers.Protocol
+		baseURL  string
+		manifest string
+		expect   []Issue
+	}{
+		{
+			name:     "when an hls master playlist has redundant pairs with codecs and resolutions, expect no issues",
+			protocol: parsers.ProtocolHLS,
+			baseURL:  "http://existing.base/uri/master.m3u8",
+			manifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:BANDWIDTH=2000,CODECS="avc1.77.30,mp4a.40.2",RESOLUTION=640x360
+link_1.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=2000,CODECS="avc1.77.30,mp4a.40.2",RESOLUTION=640x360
+http://backup.base/uri/link_1.m3u8
+`,
+		},
+		{
+			name:     "when hls variants miss codecs or resolution, expect warnings",
+			protocol: parsers.ProtocolHLS,
+			baseURL:  "http://existing.base/uri/master.m3u8",
+			manifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:BANDWIDTH=2000
+link_1.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=2000
+link_1b.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=4000,CODECS="avc1.77.30,mp4a.40.2"
+link_2.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=4000,CODECS="avc1.77.30,mp4a.40.2"
+link_2b.m3u8
+`,
+			expect: []Issue{
+				{SeverityWarning, "variant 0 has no CODECS"},
+				{SeverityWarning, "variant 1 has no CODECS"},
+				{SeverityWarning, "variant 2 carries video without RESOLUTION"},
+				{SeverityWarning, "variant 3 carries video without RESOLUTION"},
+			},
+		},
+		{
+			name:     "when hls backup variants are not in redundant pairs, expect an info",
+			protocol: parsers.ProtocolHLS,
+			baseURL:  "http://existing.base/uri/master.m3u8",
+			manifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:BANDWIDTH=2000,CODECS="mp4a.40.2"
+link_1.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=2000,CODECS="mp4a.40.2"
+http://backup.base/uri/link_1.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=4000,CODECS="mp4a.40.2"
+link_2.m3u8
+`,
+			expect: []Issue{{SeverityInfo, "variants are not in redundant pairs, DeWeave drops the unpaired variants"}},
+		},
+		{
+			name:     "when an hls master playlist is a multi codec ladder without backups, expect no issues",
+			protocol: parsers.ProtocolHLS,
+			baseURL:  "http://existing.base/uri/master.m3u8",
+			manifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:BANDWIDTH=2000,CODECS="avc1.77.30,mp4a.40.2",RESOLUTION=640x360
+avc_1.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=4000,CODECS="avc1.77.30,mp4a.40.2",RESOLUTION=1280x720
+avc_2.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=1500,CODECS="hvc1.1.6.L120.90,mp4a.40.2",RESOLUTION=1280x720
+hevc_1.m3u8
+`,
+		},
+		{
+			name:     "when hls uris are relative without an absolute base, expect errors",
+			protocol: parsers.ProtocolHLS,
+			baseURL:  "master.m3u8",
+			manifest: `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aud",NAME="English",LANGUAGE="en",URI="audio.m3u8"
+#EXT-X-STREAM-INF:BANDWIDTH=2000,CODECS="mp4a.40.2",AUDIO="aud"
+http://existing.base/uri/link_1.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=2000,CODECS="mp4a.40.2",AUDIO="aud"
+link_1.m3u8
+`,
+			expect: []Issue{
+				{SeverityError, `variant 1 has an unresolvable URI "link_1.m3u8"`},
+				{SeverityError, `AUDIO alternative "English" has an unresolvable URI "audio.m3u8"`},
+			},
+		},
+		{
+			name:     "when hls uris resolve against the base without a host, expect errors",
+			protocol: parsers.ProtocolHLS,
+			baseURL:  "http://existing.base/uri/master.m3u8",
+			manifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:BANDWIDTH=2000,CODECS="mp4a.40.2"
+//backup.base/uri/link_1.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=2000,CODECS="mp4a.40.2"
+https:link_1.m3u8
+`,
+			expect: []Issue{
+				{SeverityError, `variant 1 has an unresolvable URI "https:link_1.m3u8"`},
+			},
+		},
+		{
+			name:     "when hls uris are relative to a base without a host, expect errors",
+			protocol: parsers.ProtocolHLS,
+			baseURL:  "file:master.m3u8",
+			manifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:BANDWIDTH=2000,CODECS="mp4a.40.2"
+http://existing.base/uri/link_1.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=2000,CODECS="mp4a.40.2"
+link_1.m3u8
+`,
+			expect: []Issue{
+				{SeverityError, `variant 1 has an unresolvable URI "link_1.m3u8"`},
+			},
+		},
+		{
+			name:     "when hls segments exceed the target duration, expect errors",
+			protocol: parsers.ProtocolHLS,
+			baseURL:  "http://existing.base/uri/link_1.m3u8",
+			manifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:6
+#EXTINF:6.400,
+seg_1.ts
+#EXTINF:6.600,
+seg_2.ts
+#EXT-X-ENDLIST
+`,
+			expect: []Issue{{SeverityError, "segment 1 lasts 6.600s, more than the 6s EXT-X-TARGETDURATION"}},
+		},
+		{
+			name:     "when hls program date times leave a gap, expect a warning",
+			protocol: parsers.ProtocolHLS,
+			baseURL:  "http://existing.base/uri/link_1.m3u8",
+			manifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:6
+#EXT-X-PROGRAM-DATE-TIME:2020-01-01T00:00:00.000Z
+#EXTINF:6.000,
+seg_1.ts
+#EXT-X-PROGRAM-DATE-TIME:2020-01-01T00:00:06.050Z
+#EXTINF:6.000,
+seg_2.ts
+#EXT-X-PROGRAM-DATE-TIME:2020-01-01T00:00:14.000Z
+#EXTINF:6.000,
+seg_3.ts
+#EXT-X-DISCONTINUITY
+#EXT-X-PROGRAM-DATE-TIME:2020-01-01T01:00:00.000Z
+#EXTINF:6.000,
+seg_4.ts
+#EXT-X-ENDLIST
+`,
+			expect: []Issue{{SeverityWarning, "segment 2 EXT-X-PROGRAM-DATE-TIME is 1.95s off the end of the previous segment"}},
+		},
+		{
+			name:     "when dash ids are not unique, expect errors",
+			protocol: parsers.ProtocolDASH,
+			manifest: `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-live:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <Period id="0">
+    <AdaptationSet id="1" mimeType="video/mp4">
+      <Representation id="0" bandwidth="1000" codecs="avc1.64001f"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" mimeType="audio/mp4">
+      <Representation id="0" bandwidth="128" codecs="mp4a.40.2"></Representation>
+    </AdaptationSet>
+  </Period>
+  <Period id="0">
+    <AdaptationSet id="1" mimeType="video/mp4">
+      <Representation id="0" bandwidth="1000" codecs="avc1.64001f"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`,
+			expect: []Issue{
+				{SeverityError, "AdaptationSet id 1 is not unique in Period 0"},
+				{SeverityError, "Representation id 0 is not unique in Period 0"},
+				{SeverityError, "Period id 0 is not unique"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LintManifest(tt.protocol, tt.baseURL, tt.manifest)
+			if !cmp.Equal(got, tt.expect) {
+				t.Errorf("Wrong issues returned\ngot %v\nexpected: %v\ndiff: %v", got, tt.expect, cmp.Diff(got, tt.expect))
+			}
+		})
+	}
+}
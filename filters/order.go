@@ -0,0 +1,120 @@
+package filters
+
+import (
+	"sort"
+
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/grafov/m3u8"
+	"github.com/zencoder/go-dash/mpd"
+)
+
+// unknownBandwidth marks renditions not advertising a bandwidth, they are
+// sorted last and never moved first
+const unknownBandwidth = -1
+
+// orderRenditions returns the indexes of the renditions, given their bandwidths,
+// in the order set by the sort and first filters
+func orderRenditions(s *parsers.Sort, f *parsers.First, bandwidths []int) []int {
+	order := make([]int, len(bandwidths))
+	for i := range order {
+		order[i] = i
+	}
+
+	if s != nil {
+		sort.SliceStable(order, func(i, j int) bool {
+			a, b := bandwidths[order[i]], bandwidths[order[j]]
+			switch {
+			case a == unknownBandwidth || b == unknownBandwidth:
+				return b == unknownBandwidth && a != unknownBandwidth
+			case s.Order == parsers.SortDescending:
+				return a > b
+			}
+			return a < b
+		})
+	}
+
+	if f == nil {
+		return order
+	}
+
+	selected := -1
+	for pos, i := range order {
+		if b := bandwidths[i]; b != unknownBandwidth {
+			if selected == -1 || betterFirst(b, bandwidths[order[selected]], f.Bandwidth) {
+				selected = pos
+			}
+		}
+	}
+
+	if selected > 0 {
+		first := order[selected]
+		copy(order[1:selected+1], order[:selected])
+		order[0] = first
+	}
+
+	return order
+}
+
+// betterFirst returns true if bandwidth a is a better first rendition than b,
+// preferring the highest bandwidth not above the target, otherwise the lowest one
+func betterFirst(a, b, target int) bool {
+	switch {
+	case a <= target && b <= target:
+		return a > b
+	case a > target && b > target:
+		return a < b
+	}
+
+	return a <= target
+}
+
+// orderVariants orders the variants according to the sort and first filters.
+// I-Frame variants are not reordered and keep their position
+func orderVariants(s *parsers.Sort, f *parsers.First, variants []*m3u8.Variant) []*m3u8.Variant {
+	if s == nil && f == nil {
+		return variants
+	}
+
+	var positions []int
+	var bandwidths []int
+	for i, v := range variants {
+		if !v.Iframe {
+			positions = append(positions, i)
+			bandwidths = append(bandwidths, int(v.Bandwidth))
+		}
+	}
+
+	ordered := make([]*m3u8.Variant, len(variants))
+	copy(ordered, variants)
+	for i, index := range orderRenditions(s, f, bandwidths) {
+		ordered[positions[i]] = variants[positions[index]]
+	}
+
+	return ordered
+}
+
+// orderRepresentations orders the Representations of each AdaptationSet
+// according to the sort and first filters
+func orderRepresentations(s *parsers.Sort, f *parsers.First, manifest *mpd.MPD) {
+	if s == nil && f == nil {
+		return
+	}
+
+	for _, period := range manifest.Periods {
+		for _, as := range period.AdaptationSets {
+			bandwidths := make([]int, len(as.Representations))
+			for i, r := range as.Representations {
+				bandwidths[i] = unknownBandwidth
+				if r.Bandwidth != nil {
+					bandwidths[i] = int(*r.Bandwidth)
+				}
+			}
+
+			ordered := make([]*mpd.Representation, 0, len(as.Representations))
+			for _, index := range orderRenditions(s, f, bandwidths) {
+				ordered = append(ordered, as.Representations[index])
+			}
+			as.Representations = ordered
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package filters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cbsinteractive/bakery/config"
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOrderRenditions(t *testing.T) {
+	bandwidths := []int{2000, 500, unknownBandwidth, 4000, 1000}
+
+	tests := []struct {
+		name   string
+		sort   *parsers.Sort
+		first  *parsers.First
+		expect []int
+	}{
+		{
+			name:   "when no ordering is set, expect the origin order",
+			expect: []int{0, 1, 2, 3, 4},
+		},
+		{
+			name:   "when sorting ascending, expect unknown bandwidths last",
+			sort:   &parsers.Sort{Field: "bw", Order: parsers.SortAscending},
+			expect: []int{1, 4, 0, 3, 2},
+		},
+		{
+			name:   "when sorting descending, expect unknown bandwidths last",
+			sort:   &parsers.Sort{Field: "bw", Order: parsers.SortDescending},
+			expect: []int{3, 0, 4, 1, 2},
+		},
+		{
+			name:   "when a first bandwidth is set, expect the highest rendition not above it moved first",
+			first:  &parsers.First{Bandwidth: 1500},
+			expect: []int{4, 0, 1, 2, 3},
+		},
+		{
+			name:   "when every rendition is above the first bandwidth, expect the lowest moved first",
+			first:  &parsers.First{Bandwidth: 100},
+			expect: []int{1, 0, 2, 3, 4},
+		},
+		{
+			name:   "when sorting and a first bandwidth are set, expect the first rendition moved after sorting",
+			sort:   &parsers.Sort{Field: "bw", Order: parsers.SortDescending},
+			first:  &parsers.First{Bandwidth: 2000},
+			expect: []int{0, 3, 4, 1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := orderRenditions(tt.sort, tt.first, bandwidths)
+			if !cmp.Equal(got, tt.expect) {
+				t.Errorf("Wrong order returned\ngot %v\nexpected: %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestHLSFilter_FilterContent_Order(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/link_1.m3u8
+#EXT-X-I-FRAME-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=100,CODECS="avc1.77.30",URI="http://existing.base/uri/iframe.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=4000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/link_2.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/link_3.m3u8
+`
+
+	tests := []struct {
+		name                  string
+		filters               *parsers.MediaFilters
+		expectManifestContent string
+	}{
+		{
+			name:    "when sorting descending, expect variants reordered and i-frames kept in place",
+			filters: &parsers.MediaFilters{Sort: &parsers.Sort{Field: "bw", Order: parsers.SortDescending}},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=4000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/link_2.m3u8
+#EXT-X-I-FRAME-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=100,CODECS="avc1.77.30",URI="http://existing.base/uri/iframe.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/link_3.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/link_1.m3u8
+`,
+		},
+		{
+			name:    "when a first bandwidth is set, expect the startup variant listed first",
+			filters: &parsers.MediaFilters{First: &parsers.First{Bandwidth: 3000}},
+			expectManifestContent: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/link_3.m3u8
+#EXT-X-I-FRAME-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=100,CODECS="avc1.77.30",URI="http://existing.base/uri/iframe.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/link_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=4000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/link_2.m3u8
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+			got, err := filter.FilterContent(context.Background(), tt.filters)
+			if err != nil {
+				t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+			}
+
+			if g, e := got, tt.expectManifestContent; g != e {
+				t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e, cmp.Diff(g, e))
+			}
+		})
+	}
+}
+
+func TestDASHFilter_FilterContent_Order(t *testing.T) {
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="video">
+      <Representation bandwidth="1000" codecs="avc1.77.30" id="0"></Representation>
+      <Representation bandwidth="4000" codecs="avc1.77.30" id="1"></Representation>
+      <Representation bandwidth="2000" codecs="avc1.77.30" id="2"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" contentType="audio">
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="3"></Representation>
+      <Representation bandwidth="128" codecs="mp4a.40.2" id="4"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" contentType="video">
+      <Representation bandwidth="4000" codecs="avc1.77.30" id="1"></Representation>
+      <Representation bandwidth="2000" codecs="avc1.77.30" id="2"></Representation>
+      <Representation bandwidth="1000" codecs="avc1.77.30" id="0"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="1" contentType="audio">
+      <Representation bandwidth="128" codecs="mp4a.40.2" id="4"></Representation>
+      <Representation bandwidth="96" codecs="mp4a.40.2" id="3"></Representation>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	filter := NewDASHFilter("", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), &parsers.MediaFilters{
+		Sort: &parsers.Sort{Field: "bw", Order: parsers.SortDescending},
+	})
+	if err != nil {
+		t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", got, expect, cmp.Diff(got, expect))
+	}
+}
@@ -1,23 +1,109 @@
 package filters
 
-import "github.com/zencoder/go-dash/mpd"
+import (
+	"errors"
+	"fmt"
+	"sync"
 
-type execPluginDASH func(manifest *mpd.MPD)
+	"github.com/grafov/m3u8"
+	"github.com/zencoder/go-dash/mpd"
+)
+
+// DASHPlugin modifies a DASH manifest once it has been filtered
+type DASHPlugin func(manifest *mpd.MPD)
+
+// HLSPlugin modifies HLS playlists once they have been filtered. Master runs on
+// master playlists, where variants hold their alternatives, and Media runs on
+// media playlists and their segments. Either may be nil
+type HLSPlugin struct {
+	Master func(playlist *m3u8.MasterPlaylist)
+	Media  func(playlist *m3u8.MediaPlaylist)
+}
 
 var (
-	pluginDASH = map[string]execPluginDASH{
-		"dvsRoleOverride": dvsRoleOverride,
+	pluginsMu  sync.RWMutex
+	pluginDASH = map[string]DASHPlugin{
+		"dvsRoleOverride": rewriteDASHRoles(dvsRoleOverrideRules),
 	}
+	pluginHLS = map[string]HLSPlugin{}
 )
 
-func dvsRoleOverride(manifest *mpd.MPD) {
-	for _, period := range manifest.Periods {
-		for _, as := range period.AdaptationSets {
-			for i, access := range as.AccessibilityElems {
-				if access != nil && *access.SchemeIdUri == "urn:tva:metadata:cs:AudioPurposeCS:2007" {
-					as.Roles[i].Value = strptr("description")
-				}
-			}
+// RegisterDASHPlugin makes a DASH plugin available under the given name, which
+// is selected with the [name] path segment. It is meant to be called from init
+func RegisterDASHPlugin(name string, plugin DASHPlugin) error {
+	if plugin == nil {
+		return errors.New("registering dash plugin: plugin is nil")
+	}
+
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+
+	if err := validatePluginName(name, pluginDASH[name] != nil); err != nil {
+		return fmt.Errorf("registering dash plugin: %w", err)
+	}
+	pluginDASH[name] = plugin
+
+	return nil
+}
+
+// RegisterHLSPlugin makes an HLS plugin available under the given name, which
+// is selected with the [name] path segment. It is meant to be called from init
+func RegisterHLSPlugin(name string, plugin HLSPlugin) error {
+	if plugin.Master == nil && plugin.Media == nil {
+		return errors.New("registering hls plugin: plugin has no master nor media function")
+	}
+
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+
+	_, registered := pluginHLS[name]
+	if err := validatePluginName(name, registered); err != nil {
+		return fmt.Errorf("registering hls plugin: %w", err)
+	}
+	pluginHLS[name] = plugin
+
+	return nil
+}
+
+func validatePluginName(name string, registered bool) error {
+	switch {
+	case name == "":
+		return errors.New("plugin name is empty")
+	case registered:
+		return fmt.Errorf("plugin %q is already registered", name)
+	}
+
+	return nil
+}
+
+// dashPlugins returns the registered DASH plugins for the given names, in order.
+// Unknown names are ignored
+func dashPlugins(names []string) []DASHPlugin {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+
+	var plugins []DASHPlugin
+	for _, name := range names {
+		if plugin, ok := pluginDASH[name]; ok {
+			plugins = append(plugins, plugin)
 		}
 	}
+
+	return plugins
+}
+
+// hlsPlugins returns the registered HLS plugins for the given names, in order.
+// Unknown names are ignored
+func hlsPlugins(names []string) []HLSPlugin {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+
+	var plugins []HLSPlugin
+	for _, name := range names {
+		if plugin, ok := pluginHLS[name]; ok {
+			plugins = append(plugins, plugin)
+		}
+	}
+
+	return plugins
 }
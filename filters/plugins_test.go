@@ -0,0 +1,159 @@
+package filters
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cbsinteractive/bakery/config"
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafov/m3u8"
+	"github.com/zencoder/go-dash/mpd"
+)
+
+func TestPlugins_Register(t *testing.T) {
+	noop := HLSPlugin{Media: func(*m3u8.MediaPlaylist) {}}
+
+	tests := []struct {
+		name      string
+		register  func() error
+		expectErr bool
+	}{
+		{
+			name:     "when an hls plugin is registered, expect no error",
+			register: func() error { return RegisterHLSPlugin("testRegisterHLS", noop) },
+		},
+		{
+			name: "when an hls plugin name is already registered, expect an error",
+			register: func() error {
+				RegisterHLSPlugin("testDuplicateHLS", noop)
+				return RegisterHLSPlugin("testDuplicateHLS", noop)
+			},
+			expectErr: true,
+		},
+		{
+			name:      "when an hls plugin has no function, expect an error",
+			register:  func() error { return RegisterHLSPlugin("testEmptyHLS", HLSPlugin{}) },
+			expectErr: true,
+		},
+		{
+			name:      "when a plugin name is empty, expect an error",
+			register:  func() error { return RegisterHLSPlugin("", noop) },
+			expectErr: true,
+		},
+		{
+			name:      "when a dash plugin overrides a builtin plugin, expect an error",
+			register:  func() error { return RegisterDASHPlugin("dvsRoleOverride", func(*mpd.MPD) {}) },
+			expectErr: true,
+		},
+		{
+			name:      "when a dash plugin is nil, expect an error",
+			register:  func() error { return RegisterDASHPlugin("testNilDASH", nil) },
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.register()
+			if err != nil && !tt.expectErr {
+				t.Errorf("Register() didnt expect an error to be returned, got: %v", err)
+			} else if err == nil && tt.expectErr {
+				t.Error("Register() expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestHLSFilter_FilterContent_Plugins(t *testing.T) {
+	err := RegisterHLSPlugin("testUppercaseURIs", HLSPlugin{
+		Master: func(playlist *m3u8.MasterPlaylist) {
+			for _, v := range playlist.Variants {
+				v.URI = strings.ToUpper(v.URI)
+				for _, alt := range v.Alternatives {
+					alt.URI = strings.ToUpper(alt.URI)
+				}
+			}
+		},
+		Media: func(playlist *m3u8.MediaPlaylist) {
+			for _, segment := range playlist.Segments {
+				if segment != nil {
+					segment.URI = strings.ToUpper(segment.URI)
+				}
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterHLSPlugin() didnt expect an error to be returned, got: %v", err)
+	}
+
+	masterManifest := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2",AUDIO="aac"
+http://existing.base/uri/link_1.m3u8
+`
+
+	mediaManifest := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-MEDIA-SEQUENCE:0
+#EXT-X-TARGETDURATION:6
+#EXTINF:6.000,
+segment_1.ts
+#EXT-X-ENDLIST
+`
+
+	tests := []struct {
+		name           string
+		manifest       string
+		plugins        []string
+		expectManifest string
+	}{
+		{
+			name:     "when a plugin is selected on a master playlist, expect variants and alternatives modified",
+			manifest: masterManifest,
+			plugins:  []string{"testUppercaseURIs"},
+			expectManifest: `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="HTTP://EXISTING.BASE/URI/AUDIO.M3U8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2",AUDIO="aac"
+HTTP://EXISTING.BASE/URI/LINK_1.M3U8
+`,
+		},
+		{
+			name:     "when a plugin is selected on a media playlist, expect segments modified",
+			manifest: mediaManifest,
+			plugins:  []string{"testUppercaseURIs"},
+			expectManifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-MEDIA-SEQUENCE:0
+#EXT-X-TARGETDURATION:6
+#EXTINF:6.000,
+SEGMENT_1.TS
+#EXT-X-ENDLIST
+`,
+		},
+		{
+			name:           "when an unknown plugin is selected on a media playlist, expect the playlist untouched",
+			manifest:       mediaManifest,
+			plugins:        []string{"unknown"},
+			expectManifest: mediaManifest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewHLSFilter("http://existing.base/uri/master.m3u8", tt.manifest, config.Config{})
+			got, err := filter.FilterContent(context.Background(), &parsers.MediaFilters{Plugins: tt.plugins})
+			if err != nil {
+				t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+			}
+
+			if !cmp.Equal(got, tt.expectManifest) {
+				t.Errorf("Wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v",
+					got, tt.expectManifest, cmp.Diff(got, tt.expectManifest))
+			}
+		})
+	}
+}
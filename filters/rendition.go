@@ -0,0 +1,292 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/grafov/m3u8"
+	"github.com/zencoder/go-dash/mpd"
+)
+
+// Rendition is a protocol neutral view of an HLS variant or media alternative,
+// or of a DASH Representation, evaluated by the filter steps. Zero values mean
+// the attribute is not advertised by the manifest
+type Rendition struct {
+	// Type is empty for HLS variants as they mux several content types
+	Type             ContentType
+	Codecs           []string
+	Bandwidth        int
+	AverageBandwidth int
+	Width            int
+	Height           int
+	FrameRate        string
+	VideoRange       string
+	Channels         int
+	Language         string
+	// Roles are named after the DASH role scheme, such as main or description
+	Roles []string
+	// Forced is true for forced subtitles
+	Forced bool
+	// InstreamID is the channel of HLS closed captions, such as CC1 or SERVICE1
+	InstreamID string
+}
+
+// Number returns the numeric attribute referenced by expressions and
+// whether it is advertised
+func (r Rendition) Number(name string) (float64, bool) {
+	var v int
+	switch name {
+	case "bandwidth":
+		v = r.Bandwidth
+	case "avg_bandwidth":
+		v = r.AverageBandwidth
+	case "width":
+		v = r.Width
+	case "height":
+		v = r.Height
+	case "channels":
+		v = r.Channels
+	case "fps":
+		return parsers.ParseFrameRate(r.FrameRate)
+	}
+
+	return float64(v), v != 0
+}
+
+// Strings returns the string attribute referenced by expressions, nil when
+// it is not advertised
+func (r Rendition) Strings(name string) []string {
+	var v string
+	switch name {
+	case "codecs":
+		return r.Codecs
+	case "type":
+		var types []string
+		for _, ct := range r.contentTypes() {
+			types = append(types, string(ct))
+		}
+		return types
+	case "lang":
+		v = r.Language
+	case "video_range":
+		v = r.VideoRange
+	}
+
+	if v == "" {
+		return nil
+	}
+
+	return []string{v}
+}
+
+// contentTypes returns the content types carried by the rendition. Renditions
+// without a type are typed after their codecs
+func (r Rendition) contentTypes() []ContentType {
+	if r.Type != "" {
+		return []ContentType{r.Type}
+	}
+
+	var types []ContentType
+	for _, ct := range []ContentType{videoContentType, audioContentType, captionContentType} {
+		if len(r.codecs(ct)) > 0 {
+			types = append(types, ct)
+		}
+	}
+
+	return types
+}
+
+// codecs returns the codecs of the rendition for the given content type
+func (r Rendition) codecs(ct ContentType) []string {
+	if r.Type != "" {
+		if r.Type == ct {
+			return r.Codecs
+		}
+		return nil
+	}
+
+	match, found := matchFunctions[ct]
+	if !found {
+		return nil
+	}
+
+	var codecs []string
+	for _, codec := range r.Codecs {
+		if match(codec) {
+			codecs = append(codecs, codec)
+		}
+	}
+
+	return codecs
+}
+
+// newVariantRendition adapts an HLS variant
+func newVariantRendition(v *m3u8.Variant) Rendition {
+	r := Rendition{
+		Bandwidth:        int(v.Bandwidth),
+		AverageBandwidth: int(v.AverageBandwidth),
+		Codecs:           splitList(v.Codecs),
+	}
+	r.VideoRange = videoRange(v.VideoRange, r.codecs(videoContentType))
+
+	if v.FrameRate != 0 {
+		r.FrameRate = fmt.Sprintf("%.3f", v.FrameRate)
+	}
+
+	if res := strings.SplitN(v.Resolution, "x", 2); len(res) == 2 {
+		r.Width, r.Height = atoi(res[0]), atoi(res[1])
+	}
+
+	return r
+}
+
+// newAlternativeRendition adapts an HLS media alternative along with its CHANNELS
+// attribute, which the playlist decoder does not keep
+func newAlternativeRendition(a *m3u8.Alternative, channels string) Rendition {
+	r := Rendition{
+		Language: a.Language,
+		Roles:    alternativeRoles(a.Characteristics),
+	}
+
+	switch a.Type {
+	case "AUDIO":
+		r.Type = audioContentType
+		r.Channels = parseChannels(channels)
+	case "VIDEO":
+		r.Type = videoContentType
+	case "SUBTITLES":
+		r.Type = captionContentType
+		r.Forced = strings.EqualFold(a.Forced, "YES")
+	case "CLOSED-CAPTIONS":
+		r.Type = captionContentType
+		r.InstreamID = a.InstreamID
+	}
+
+	return r
+}
+
+// newRepresentationRendition adapts a DASH Representation, attributes missing from
+// the Representation are inherited from its AdaptationSet. A nil Representation
+// adapts the AdaptationSet alone
+func newRepresentationRendition(as *mpd.AdaptationSet, rep *mpd.Representation) Rendition {
+	r := Rendition{
+		Type:      ContentType(strval(as.ContentType)),
+		Codecs:    splitList(strval(as.Codecs)),
+		Language:  strval(as.Lang),
+		FrameRate: strval(as.FrameRate),
+		Width:     atoi(strval(as.Width)),
+		Height:    atoi(strval(as.Height)),
+		Roles:     adaptationSetRoles(as),
+	}
+
+	for _, role := range r.Roles {
+		r.Forced = r.Forced || role == forcedSubtitleRole
+	}
+
+	for _, acc := range as.AudioChannelConfiguration {
+		r.Channels = channelCount(acc.SchemeIDURI, acc.Value)
+	}
+
+	if rep == nil {
+		r.VideoRange = videoRange(transferCharacteristics(as.CommonAttributesAndElements), r.codecs(videoContentType))
+		return r
+	}
+
+	if acc := rep.AudioChannelConfiguration; acc != nil {
+		r.Channels = channelCount(acc.SchemeIDURI, acc.Value)
+	}
+
+	if rep.Codecs != nil {
+		r.Codecs = splitList(*rep.Codecs)
+	}
+	if rep.FrameRate != nil {
+		r.FrameRate = *rep.FrameRate
+	}
+	if rep.Bandwidth != nil {
+		r.Bandwidth = int(*rep.Bandwidth)
+	}
+	if rep.Width != nil {
+		r.Width = int(*rep.Width)
+	}
+	if rep.Height != nil {
+		r.Height = int(*rep.Height)
+	}
+
+	signaled := transferCharacteristics(rep.CommonAttributesAndElements)
+	if signaled == "" {
+		signaled = transferCharacteristics(as.CommonAttributesAndElements)
+	}
+	r.VideoRange = videoRange(signaled, r.codecs(videoContentType))
+
+	return r
+}
+
+// Video ranges, as signaled by the HLS VIDEO-RANGE attribute
+const (
+	sdrVideoRange = "SDR"
+	pqVideoRange  = "PQ"
+	hlgVideoRange = "HLG"
+)
+
+// cicpTransferCharacteristics is the scheme of DASH descriptors signaling the
+// transfer characteristics of the video, as defined by ISO/IEC 23001-8
+const cicpTransferCharacteristics = "urn:mpeg:mpegB:cicp:TransferCharacteristics"
+
+// transferCharacteristics returns the video range signaled by the CICP descriptors
+// of a DASH AdaptationSet or Representation, empty if none is signaled
+func transferCharacteristics(c mpd.CommonAttributesAndElements) string {
+	descriptors := append(append([]mpd.DescriptorType{}, c.EssentialProperty...), c.SupplementalProperty...)
+	for _, d := range descriptors {
+		if strval(d.SchemeIDURI) != cicpTransferCharacteristics {
+			continue
+		}
+
+		switch strval(d.Value) {
+		case "16":
+			return pqVideoRange
+		case "18":
+			return hlgVideoRange
+		case "1", "6", "13", "14", "15":
+			return sdrVideoRange
+		}
+	}
+
+	return ""
+}
+
+// videoRange returns the signaled video range or, when missing, the one implied
+// by the video codecs. Dolby Vision and HEVC Main 10 imply PQ, as the hdr10 codec
+// filter assumes, while other video defaults to SDR
+func videoRange(signaled string, videoCodecs []string) string {
+	if signaled != "" {
+		return strings.ToUpper(signaled)
+	}
+
+	for _, codec := range videoCodecs {
+		if ValidCodecs(codec, dolbyCodec) || strings.HasPrefix(codec, "hvc1.2") || strings.HasPrefix(codec, "hev1.2") {
+			return pqVideoRange
+		}
+	}
+
+	if len(videoCodecs) > 0 {
+		return sdrVideoRange
+	}
+
+	return ""
+}
+
+// splitList splits a comma separated attribute, such as CODECS
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, ",")
+}
+
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
@@ -0,0 +1,103 @@
+package filters
+
+import (
+	"testing"
+
+	"github.com/grafov/m3u8"
+	"github.com/zencoder/go-dash/mpd"
+)
+
+func TestRendition_VideoRange(t *testing.T) {
+	cicp := func(value string) []mpd.DescriptorType {
+		return []mpd.DescriptorType{{SchemeIDURI: strptr(cicpTransferCharacteristics), Value: strptr(value)}}
+	}
+
+	tests := []struct {
+		name      string
+		rendition Rendition
+		expect    string
+	}{
+		{
+			name: "when an hls variant signals its video range, expect it used",
+			rendition: newVariantRendition(&m3u8.Variant{VariantParams: m3u8.VariantParams{
+				Codecs: "avc1.640029,mp4a.40.2", VideoRange: "PQ",
+			}}),
+			expect: pqVideoRange,
+		},
+		{
+			name: "when an hls variant is dolby vision without a video range, expect pq",
+			rendition: newVariantRendition(&m3u8.Variant{VariantParams: m3u8.VariantParams{
+				Codecs: "dvh1.05.06,ec-3",
+			}}),
+			expect: pqVideoRange,
+		},
+		{
+			name: "when an hls variant is hevc main 10 without a video range, expect pq",
+			rendition: newVariantRendition(&m3u8.Variant{VariantParams: m3u8.VariantParams{
+				Codecs: "hvc1.2.4.L153.B0,mp4a.40.2",
+			}}),
+			expect: pqVideoRange,
+		},
+		{
+			name: "when an hls variant is avc without a video range, expect sdr",
+			rendition: newVariantRendition(&m3u8.Variant{VariantParams: m3u8.VariantParams{
+				Codecs: "avc1.640029,mp4a.40.2",
+			}}),
+			expect: sdrVideoRange,
+		},
+		{
+			name: "when an hls variant is audio only, expect no video range",
+			rendition: newVariantRendition(&m3u8.Variant{VariantParams: m3u8.VariantParams{
+				Codecs: "mp4a.40.2",
+			}}),
+		},
+		{
+			name: "when a dash representation signals hlg, expect hlg regardless of its codec",
+			rendition: newRepresentationRendition(
+				&mpd.AdaptationSet{ContentType: strptr("video")},
+				&mpd.Representation{
+					CommonAttributesAndElements: mpd.CommonAttributesAndElements{SupplementalProperty: cicp("18")},
+					Codecs:                      strptr("hvc1.2.4.L153.B0"),
+				},
+			),
+			expect: hlgVideoRange,
+		},
+		{
+			name: "when a dash adaptation set signals pq, expect its representations to inherit it",
+			rendition: newRepresentationRendition(
+				&mpd.AdaptationSet{
+					CommonAttributesAndElements: mpd.CommonAttributesAndElements{EssentialProperty: cicp("16")},
+					ContentType:                 strptr("video"),
+				},
+				&mpd.Representation{Codecs: strptr("avc1.640029")},
+			),
+			expect: pqVideoRange,
+		},
+		{
+			name: "when a dash representation signals bt.709, expect sdr",
+			rendition: newRepresentationRendition(
+				&mpd.AdaptationSet{
+					CommonAttributesAndElements: mpd.CommonAttributesAndElements{SupplementalProperty: cicp("1")},
+					ContentType:                 strptr("video"),
+				},
+				&mpd.Representation{Codecs: strptr("hvc1.2.4.L153.B0")},
+			),
+			expect: sdrVideoRange,
+		},
+		{
+			name: "when a dash representation is audio, expect no video range",
+			rendition: newRepresentationRendition(
+				&mpd.AdaptationSet{ContentType: strptr("audio")},
+				&mpd.Representation{Codecs: strptr("mp4a.40.2")},
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rendition.VideoRange; got != tt.expect {
+				t.Errorf("Wrong video range\ngot %q\nexpected: %q", got, tt.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/grafov/m3u8"
+	"github.com/zencoder/go-dash/mpd"
+)
+
+// RewriteRule rewrites the role of the renditions signaling a descriptor, such
+// as flagging DVS audio as description. DASH AdaptationSets are matched on
+// Scheme and Value, HLS alternatives on Characteristic
+type RewriteRule struct {
+	// Scheme matches the Accessibility and Role descriptors of AdaptationSets
+	Scheme string `json:"scheme,omitempty"`
+	// Value matches the descriptor value, empty matching any value of Scheme
+	Value string `json:"value,omitempty"`
+	// Characteristic matches one of the CHARACTERISTICS of HLS alternatives
+	Characteristic string `json:"characteristic,omitempty"`
+	// Role sets the value of the AdaptationSet Role in the DASH role scheme,
+	// adding the Role when missing, and adds the equivalent characteristic to
+	// the CHARACTERISTICS of HLS alternatives
+	Role string `json:"role,omitempty"`
+	// Label sets the NAME of HLS alternatives. The DASH manifest writer does not
+	// support Label elements, so rules matching a Scheme cannot set one
+	Label string `json:"label,omitempty"`
+	// Characteristics sets the CHARACTERISTICS of HLS alternatives
+	Characteristics string `json:"characteristics,omitempty"`
+}
+
+// dvsRoleOverrideRules flags the AdaptationSets signaling any DVB audio
+// purpose with the description role
+var dvsRoleOverrideRules = []RewriteRule{
+	{Scheme: audioPurposeScheme, Role: "description"},
+}
+
+// RegisterRewriteRules makes the rewrite rules available as both a DASH and an
+// HLS plugin under the given name, which is selected with the [name] path segment
+func RegisterRewriteRules(name string, rules []RewriteRule) error {
+	if len(rules) == 0 {
+		return errors.New("registering rewrite rules: no rules")
+	}
+
+	for i, rule := range rules {
+		if rule.Scheme == "" && rule.Characteristic == "" {
+			return fmt.Errorf("registering rewrite rules: rule %d matches no scheme nor characteristic", i)
+		}
+
+		if rule.Scheme != "" && rule.Label != "" {
+			return fmt.Errorf("registering rewrite rules: rule %d sets a label, which is not supported on DASH", i)
+		}
+	}
+
+	if err := RegisterDASHPlugin(name, rewriteDASHRoles(rules)); err != nil {
+		return fmt.Errorf("registering rewrite rules: %w", err)
+	}
+
+	if err := RegisterHLSPlugin(name, HLSPlugin{Master: rewriteHLSRoles(rules)}); err != nil {
+		return fmt.Errorf("registering rewrite rules: %w", err)
+	}
+
+	return nil
+}
+
+// rewriteDASHRoles applies the rules to the AdaptationSets of each Period
+func rewriteDASHRoles(rules []RewriteRule) DASHPlugin {
+	return func(manifest *mpd.MPD) {
+		for _, period := range manifest.Periods {
+			for _, as := range period.AdaptationSets {
+				var matched []RewriteRule
+				for _, rule := range rules {
+					if rule.Scheme != "" && rule.matchDASH(as) {
+						matched = append(matched, rule)
+					}
+				}
+
+				// rules are matched on the original descriptors, so that a rule
+				// rewriting the Role does not change the rules applied after it
+				for _, rule := range matched {
+					if rule.Role != "" {
+						setDASHRole(as, rule.Role)
+					}
+				}
+			}
+		}
+	}
+}
+
+// matchDASH returns true if any Accessibility or Role descriptor of the
+// AdaptationSet matches the rule scheme and value
+func (rule RewriteRule) matchDASH(as *mpd.AdaptationSet) bool {
+	for _, acc := range as.AccessibilityElems {
+		if acc != nil && rule.matchDescriptor(strval(acc.SchemeIdUri), strval(acc.Value)) {
+			return true
+		}
+	}
+
+	for _, role := range as.Roles {
+		if role != nil && rule.matchDescriptor(strval(role.SchemeIDURI), strval(role.Value)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (rule RewriteRule) matchDescriptor(scheme, value string) bool {
+	return scheme == rule.Scheme && (rule.Value == "" || value == rule.Value)
+}
+
+// setDASHRole sets the value of the Role in the DASH role scheme, adding it if
+// the AdaptationSet has none
+func setDASHRole(as *mpd.AdaptationSet, value string) {
+	if role := dashRole(as); role != nil {
+		role.Value = strptr(value)
+		return
+	}
+
+	as.Roles = append(as.Roles, &mpd.Role{SchemeIDURI: strptr(dashRoleScheme), Value: strptr(value)})
+}
+
+// rewriteHLSRoles applies the rules to the alternatives of each variant
+func rewriteHLSRoles(rules []RewriteRule) func(playlist *m3u8.MasterPlaylist) {
+	return func(playlist *m3u8.MasterPlaylist) {
+		for _, alt := range uniqueAlternatives(playlist.Variants) {
+			var matched []RewriteRule
+			for _, rule := range rules {
+				if rule.Characteristic != "" && matchFold(rule.Characteristic, splitList(alt.Characteristics)) {
+					matched = append(matched, rule)
+				}
+			}
+
+			for _, rule := range matched {
+				if rule.Label != "" {
+					alt.Name = rule.Label
+				}
+				if rule.Characteristics != "" {
+					alt.Characteristics = rule.Characteristics
+				}
+				if c, found := roleCharacteristics[rule.Role]; found {
+					alt.Characteristics = addCharacteristic(alt.Characteristics, c)
+				}
+			}
+		}
+	}
+}
+
+// addCharacteristic appends the characteristic to the comma separated
+// CHARACTERISTICS unless already present
+func addCharacteristic(characteristics, c string) string {
+	list := splitList(characteristics)
+	if matchFold(c, list) {
+		return characteristics
+	}
+
+	return strings.Join(append(list, c), ",")
+}
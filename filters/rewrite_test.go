@@ -0,0 +1,191 @@
+package filters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cbsinteractive/bakery/config"
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRewriteRules_Register(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     []RewriteRule
+		expectErr bool
+	}{
+		{
+			name:  "when rules match a scheme, expect no error",
+			rules: []RewriteRule{{Scheme: audioPurposeScheme, Role: "description"}},
+		},
+		{
+			name:      "when no rules are given, expect an error",
+			expectErr: true,
+		},
+		{
+			name:      "when a rule matches no scheme nor characteristic, expect an error",
+			rules:     []RewriteRule{{Role: "description"}},
+			expectErr: true,
+		},
+		{
+			name:      "when a rule matching a scheme sets a label, expect an error",
+			rules:     []RewriteRule{{Scheme: audioPurposeScheme, Label: "Audio Description"}},
+			expectErr: true,
+		},
+		{
+			name:  "when a rule matching a characteristic sets a label, expect no error",
+			rules: []RewriteRule{{Characteristic: "public.accessibility.describes-video", Label: "Audio Description"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterRewriteRules("testRegisterRewriteRules"+tt.name, tt.rules)
+			if err != nil && !tt.expectErr {
+				t.Errorf("RegisterRewriteRules() didnt expect an error to be returned, got: %v", err)
+			} else if err == nil && tt.expectErr {
+				t.Error("RegisterRewriteRules() expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestDASHFilter_FilterContent_RewriteRules(t *testing.T) {
+	err := RegisterRewriteRules("testDASHRewriteRules", []RewriteRule{
+		{Scheme: audioPurposeScheme, Value: "1", Role: "description"},
+		{Scheme: "urn:mpeg:dash:role:2011", Value: "commentary", Role: "alternate"},
+	})
+	if err != nil {
+		t.Fatalf("RegisterRewriteRules() didnt expect an error to be returned, got: %v", err)
+	}
+
+	manifest := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="en" contentType="audio">
+      <Representation bandwidth="256" codecs="ac-3" id="0"></Representation>
+      <Accessibility schemeIdUri="urn:scte:dash:cc:cea-608:2015" value="CC1=eng"></Accessibility>
+      <Accessibility schemeIdUri="urn:tva:metadata:cs:AudioPurposeCS:2007" value="1"></Accessibility>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="en" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="commentary"></Role>
+      <Representation bandwidth="256" codecs="ac-3" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="2" lang="en" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="main"></Role>
+      <Representation bandwidth="256" codecs="ac-3" id="2"></Representation>
+      <Accessibility schemeIdUri="urn:tva:metadata:cs:AudioPurposeCS:2007" value="2"></Accessibility>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <BaseURL>http://existing.base/url/</BaseURL>
+  <Period>
+    <AdaptationSet id="0" lang="en" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="description"></Role>
+      <Representation bandwidth="256" codecs="ac-3" id="0"></Representation>
+      <Accessibility schemeIdUri="urn:scte:dash:cc:cea-608:2015" value="CC1=eng"></Accessibility>
+      <Accessibility schemeIdUri="urn:tva:metadata:cs:AudioPurposeCS:2007" value="1"></Accessibility>
+    </AdaptationSet>
+    <AdaptationSet id="1" lang="en" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="alternate"></Role>
+      <Representation bandwidth="256" codecs="ac-3" id="1"></Representation>
+    </AdaptationSet>
+    <AdaptationSet id="2" lang="en" contentType="audio">
+      <Role schemeIdUri="urn:mpeg:dash:role:2011" value="main"></Role>
+      <Representation bandwidth="256" codecs="ac-3" id="2"></Representation>
+      <Accessibility schemeIdUri="urn:tva:metadata:cs:AudioPurposeCS:2007" value="2"></Accessibility>
+    </AdaptationSet>
+  </Period>
+</MPD>
+`
+
+	filter := NewDASHFilter("", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), &parsers.MediaFilters{Plugins: []string{"testDASHRewriteRules"}})
+	if err != nil {
+		t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e, cmp.Diff(g, e))
+	}
+}
+
+func TestHLSFilter_FilterContent_RewriteRules(t *testing.T) {
+	err := RegisterRewriteRules("testHLSRewriteRules", []RewriteRule{
+		{
+			Characteristic:  "public.accessibility.describes-video",
+			Label:           "English (Audio Description)",
+			Characteristics: "public.accessibility.describes-video,public.main-program-content",
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterRewriteRules() didnt expect an error to be returned, got: %v", err)
+	}
+
+	manifest := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,LANGUAGE="en",CHANNELS="2",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="DVS",DEFAULT=NO,LANGUAGE="en",CHARACTERISTICS="public.accessibility.describes-video",CHANNELS="2",URI="http://existing.base/uri/audio_en_dvs.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2",AUDIO="aac"
+http://existing.base/uri/link_1.m3u8
+`
+
+	expect := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8",CHANNELS="2"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English (Audio Description)",DEFAULT=NO,LANGUAGE="en",CHARACTERISTICS="public.accessibility.describes-video,public.main-program-content",URI="http://existing.base/uri/audio_en_dvs.m3u8",CHANNELS="2"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2",AUDIO="aac"
+http://existing.base/uri/link_1.m3u8
+`
+
+	filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), &parsers.MediaFilters{Plugins: []string{"testHLSRewriteRules"}})
+	if err != nil {
+		t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e, cmp.Diff(g, e))
+	}
+}
+
+func TestHLSFilter_FilterContent_RewriteRulesRole(t *testing.T) {
+	err := RegisterRewriteRules("testHLSRewriteRulesRole", []RewriteRule{
+		{Characteristic: "public.main-program-content", Role: "description"},
+	})
+	if err != nil {
+		t.Fatalf("RegisterRewriteRules() didnt expect an error to be returned, got: %v", err)
+	}
+
+	manifest := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="DVS",DEFAULT=NO,LANGUAGE="en",CHARACTERISTICS="public.main-program-content",URI="http://existing.base/uri/audio_en_dvs.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2",AUDIO="aac"
+http://existing.base/uri/link_1.m3u8
+`
+
+	expect := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="DVS",DEFAULT=NO,LANGUAGE="en",CHARACTERISTICS="public.main-program-content,public.accessibility.describes-video",URI="http://existing.base/uri/audio_en_dvs.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2",AUDIO="aac"
+http://existing.base/uri/link_1.m3u8
+`
+
+	filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), &parsers.MediaFilters{Plugins: []string{"testHLSRewriteRulesRole"}})
+	if err != nil {
+		t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+	}
+
+	if g, e := got, expect; g != e {
+		t.Errorf("FilterContent() wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e, cmp.Diff(g, e))
+	}
+}
@@ -0,0 +1,65 @@
+package filters
+
+import "github.com/zencoder/go-dash/mpd"
+
+// audioPurposeScheme is the DVB scheme of DASH Accessibility descriptors
+// signaling the purpose of audio, 1 being audio description
+const audioPurposeScheme = "urn:tva:metadata:cs:AudioPurposeCS:2007"
+
+// characteristicRoles maps the HLS CHARACTERISTICS to the equivalent role of
+// the DASH role scheme
+var characteristicRoles = map[string]string{
+	"public.accessibility.describes-video":           "description",
+	"public.accessibility.transcribes-spoken-dialog": "caption",
+	"public.accessibility.describes-music-and-sound": "caption",
+}
+
+// roleCharacteristics maps the roles of the DASH role scheme to the equivalent
+// HLS CHARACTERISTICS
+var roleCharacteristics = map[string]string{
+	"description": "public.accessibility.describes-video",
+	"caption":     "public.accessibility.transcribes-spoken-dialog",
+}
+
+// alternativeRoles returns the roles of an HLS media alternative from its
+// CHARACTERISTICS, characteristics without a DASH equivalent being kept as is
+func alternativeRoles(characteristics string) []string {
+	var roles []string
+	for _, c := range splitList(characteristics) {
+		if role, found := characteristicRoles[c]; found {
+			c = role
+		}
+		roles = append(roles, c)
+	}
+
+	return roles
+}
+
+// adaptationSetRoles returns the roles of a DASH AdaptationSet signaled by its
+// Role descriptors and by its Accessibility descriptors in the role scheme or
+// flagging audio description
+func adaptationSetRoles(as *mpd.AdaptationSet) []string {
+	var roles []string
+	for _, role := range as.Roles {
+		if role != nil && role.Value != nil {
+			roles = append(roles, *role.Value)
+		}
+	}
+
+	for _, acc := range as.AccessibilityElems {
+		if acc == nil {
+			continue
+		}
+
+		switch strval(acc.SchemeIdUri) {
+		case dashRoleScheme:
+			roles = append(roles, strval(acc.Value))
+		case audioPurposeScheme:
+			if strval(acc.Value) == "1" {
+				roles = append(roles, "description")
+			}
+		}
+	}
+
+	return roles
+}
@@ -0,0 +1,174 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/grafov/m3u8"
+)
+
+const sessionDataTag = "#EXT-X-SESSION-DATA:"
+
+// sessionTags returns the EXT-X-SESSION-DATA and EXT-X-SESSION-KEY tags of the
+// filtered master playlist: the ones of the origin, the configured session data
+// and the one of the filters, replacing the entries with the same DATA-ID and
+// LANGUAGE, followed by the keys hoisted from the variant when asked to
+func (h *HLSFilter) sessionTags(ctx context.Context, filters *parsers.MediaFilters, content, variantURI string) ([]string, error) {
+	data := mergeSessionData(h.config.SessionData, filters.SessionData)
+
+	var tags []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, sessionKeyTag):
+			tags = append(tags, line)
+		case strings.HasPrefix(line, sessionDataTag):
+			attrs := m3u8.DecodeAttributeList(strings.TrimPrefix(line, sessionDataTag))
+			if indexSessionData(data, attrs["DATA-ID"], attrs["LANGUAGE"]) < 0 {
+				tags = append(tags, line)
+			}
+		}
+	}
+
+	for _, d := range data {
+		tags = append(tags, sessionDataLine(d))
+	}
+
+	if !filters.SessionKeys || variantURI == "" {
+		return tags, nil
+	}
+
+	keys, err := h.hoistSessionKeys(ctx, variantURI, filters.DRMSystems)
+	if err != nil {
+		return nil, fmt.Errorf("hoisting session keys: %w", err)
+	}
+
+	for _, key := range keys {
+		if !containsString(tags, key) {
+			tags = append(tags, key)
+		}
+	}
+
+	return tags, nil
+}
+
+// mergeSessionData returns the configured session data along with the one of
+// the filters, which replaces the entries with the same DATA-ID and LANGUAGE
+func mergeSessionData(configured, filtered []parsers.SessionData) []parsers.SessionData {
+	data := append([]parsers.SessionData{}, configured...)
+	for _, d := range filtered {
+		if i := indexSessionData(data, d.ID, d.Language); i >= 0 {
+			data[i] = d
+			continue
+		}
+		data = append(data, d)
+	}
+
+	return data
+}
+
+func indexSessionData(data []parsers.SessionData, id, language string) int {
+	for i, d := range data {
+		if d.ID == id && strings.EqualFold(d.Language, language) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func sessionDataLine(d parsers.SessionData) string {
+	var sb strings.Builder
+	sb.WriteString(sessionDataTag)
+	sb.WriteString(`DATA-ID="` + d.ID + `"`)
+	if d.Value != "" {
+		sb.WriteString(`,VALUE="` + d.Value + `"`)
+	} else {
+		sb.WriteString(`,URI="` + d.URI + `"`)
+	}
+	if d.Language != "" {
+		sb.WriteString(`,LANGUAGE="` + d.Language + `"`)
+	}
+
+	return sb.String()
+}
+
+// hoistSessionKeys fetches the variant playlist and returns its EXT-X-KEY tags,
+// of the DRM systems kept, as EXT-X-SESSION-KEY tags so that players can start
+// acquiring licenses before fetching it. Key URIs are made absolute as they are
+// relative to the variant playlist
+func (h *HLSFilter) hoistSessionKeys(ctx context.Context, variantURI string, systems []string) ([]string, error) {
+	info, err := fetchVariant(ctx, variantURI, h.config.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	if sc := info.Status; sc/100 > 3 {
+		return nil, fmt.Errorf("fetching variant: returning http status of %v", sc)
+	}
+
+	absolute, err := getAbsoluteURL(variantURI)
+	if err != nil {
+		return nil, fmt.Errorf("formatting key URLs: %w", err)
+	}
+
+	var keys []string
+	for _, line := range strings.Split(filterKeyTags(info.Payload, systems), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, keyTag) {
+			continue
+		}
+
+		attrs := m3u8.DecodeAttributeList(strings.TrimPrefix(line, keyTag))
+		if attrs["METHOD"] == "NONE" {
+			continue
+		}
+
+		if uri := attrs["URI"]; uri != "" {
+			abs, err := combinedIfRelative(uri, *absolute)
+			if err != nil {
+				return nil, fmt.Errorf("formatting key URLs: %w", err)
+			}
+			line = strings.Replace(line, `URI="`+uri+`"`, `URI="`+abs+`"`, 1)
+		}
+
+		key := sessionKeyTag + strings.TrimPrefix(line, keyTag)
+		if !containsString(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+// restoreSessionTags writes the session tags back to an encoded master playlist,
+// after its EXT-X-VERSION tag, as the playlist decoder does not keep them
+func restoreSessionTags(manifest string, tags []string) string {
+	if len(tags) == 0 {
+		return manifest
+	}
+
+	lines := strings.Split(manifest, "\n")
+	at := 1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#EXT-X-VERSION:") {
+			at = i + 1
+			break
+		}
+	}
+
+	restored := append(append(append([]string{}, lines[:at]...), tags...), lines[at:]...)
+	return strings.Join(restored, "\n")
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,141 @@
+package filters
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cbsinteractive/bakery/config"
+	"github.com/cbsinteractive/bakery/parsers"
+	test "github.com/cbsinteractive/bakery/tests"
+	"github.com/cbsinteractive/pkg/tracing"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHLSFilter_FilterContent_SessionTags(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-SESSION-DATA:DATA-ID="com.example.content-id",VALUE="origin"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a.40.2"
+video/link_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=4000,CODECS="avc1.77.30,mp4a.40.2"
+video/link_2.m3u8
+`
+
+	variant := `#EXTM3U
+#EXT-X-VERSION:5
+#EXT-X-TARGETDURATION:6
+#EXT-X-KEY:METHOD=SAMPLE-AES,URI="skd://key",KEYFORMAT="com.apple.streamingkeydelivery",KEYFORMATVERSIONS="1"
+#EXT-X-KEY:METHOD=SAMPLE-AES,URI="keys/key.bin",KEYFORMATVERSIONS="1"
+#EXTINF:6.000,
+seg_1.ts
+#EXT-X-KEY:METHOD=SAMPLE-AES,URI="skd://key",KEYFORMAT="com.apple.streamingkeydelivery",KEYFORMATVERSIONS="1"
+#EXTINF:6.000,
+seg_2.ts
+#EXT-X-ENDLIST
+`
+
+	tests := []struct {
+		name        string
+		filters     *parsers.MediaFilters
+		sessionData parsers.SessionDataList
+		status      int
+		expectURL   string
+		expect      string
+		expectErr   bool
+	}{
+		{
+			name:      "when session keys are hoisted, expect the keys of the first variant kept as session keys",
+			filters:   &parsers.MediaFilters{SessionKeys: true, Videos: parsers.NestedFilters{Bitrate: &parsers.Bitrate{Min: 3000, Max: 5000}}},
+			status:    200,
+			expectURL: "http://existing.base/uri/video/link_2.m3u8",
+			expect: `#EXTM3U
+#EXT-X-VERSION:5
+#EXT-X-SESSION-DATA:DATA-ID="com.example.content-id",VALUE="origin"
+#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI="skd://key",KEYFORMAT="com.apple.streamingkeydelivery",KEYFORMATVERSIONS="1"
+#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI="http://existing.base/uri/video/keys/key.bin",KEYFORMATVERSIONS="1"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=4000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/video/link_2.m3u8
+`,
+		},
+		{
+			name:      "when session keys are hoisted along with a drm filter, expect the keys of the other systems to be left out",
+			filters:   &parsers.MediaFilters{SessionKeys: true, DRMSystems: []string{"fairplay"}, Videos: parsers.NestedFilters{Bitrate: &parsers.Bitrate{Min: 0, Max: 3000}}},
+			status:    200,
+			expectURL: "http://existing.base/uri/video/link_1.m3u8",
+			expect: `#EXTM3U
+#EXT-X-VERSION:5
+#EXT-X-SESSION-DATA:DATA-ID="com.example.content-id",VALUE="origin"
+#EXT-X-SESSION-KEY:METHOD=SAMPLE-AES,URI="skd://key",KEYFORMAT="com.apple.streamingkeydelivery",KEYFORMATVERSIONS="1"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/video/link_1.m3u8
+`,
+		},
+		{
+			name:      "when the variant cannot be fetched, expect an error",
+			filters:   &parsers.MediaFilters{SessionKeys: true},
+			status:    500,
+			expectErr: true,
+		},
+		{
+			name: "when session data is configured and set by the filters, expect the filters to replace the same ids",
+			filters: &parsers.MediaFilters{Videos: parsers.NestedFilters{Bitrate: &parsers.Bitrate{Min: 0, Max: 3000}}, SessionData: []parsers.SessionData{
+				{ID: "com.example.content-id", Value: "1234"},
+				{ID: "com.example.title", Value: "Discovery", Language: "en"},
+			}},
+			sessionData: parsers.SessionDataList{
+				{ID: "com.example.chapters", URI: "https://cdn.com/chapters.json"},
+				{ID: "com.example.title", Value: "Default", Language: "en"},
+			},
+			expect: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-SESSION-DATA:DATA-ID="com.example.chapters",URI="https://cdn.com/chapters.json"
+#EXT-X-SESSION-DATA:DATA-ID="com.example.title",VALUE="Discovery",LANGUAGE="en"
+#EXT-X-SESSION-DATA:DATA-ID="com.example.content-id",VALUE="1234"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a.40.2"
+http://existing.base/uri/video/link_1.m3u8
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetched []string
+			cfg := config.Config{
+				SessionData: tt.sessionData,
+				Client: config.Client{
+					Timeout: 5 * time.Second,
+					Tracer:  tracing.NoopTracer{},
+					HTTPClient: test.MockClient(func(req *http.Request) (*http.Response, error) {
+						fetched = append(fetched, req.URL.String())
+						return &http.Response{
+							StatusCode: tt.status,
+							Body:       ioutil.NopCloser(bytes.NewBufferString(variant)),
+							Header:     http.Header{},
+						}, nil
+					}),
+				},
+			}
+
+			filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, cfg)
+			got, err := filter.FilterContent(context.Background(), tt.filters)
+			if err != nil && !tt.expectErr {
+				t.Fatalf("FilterContent(context.Background(), ) didnt expect an error to be returned, got: %v", err)
+			} else if err == nil && tt.expectErr {
+				t.Fatal("FilterContent(context.Background(), ) expected an error, got nil")
+			}
+
+			if tt.expectURL != "" && !cmp.Equal(fetched, []string{tt.expectURL}) {
+				t.Errorf("expected only %v to be fetched, got %v", tt.expectURL, fetched)
+			}
+
+			if g, e := got, tt.expect; g != e {
+				t.Errorf("FilterContent(context.Background(), ) wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", g, e,
+					cmp.Diff(g, e))
+			}
+		})
+	}
+}
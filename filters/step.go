@@ -0,0 +1,309 @@
+package filters
+
+import (
+	"strings"
+
+	"github.com/cbsinteractive/bakery/parsers"
+)
+
+// filterStep is a filter written once against the Rendition model. The HLS and
+// DASH filters adapt their manifests to renditions and remove the ones matched
+type filterStep struct {
+	name FilterName
+	// enabled returns true if the step has anything to filter
+	enabled func(filters *parsers.MediaFilters) bool
+	// remove returns true if the rendition should be removed
+	remove func(filters *parsers.MediaFilters, r Rendition) bool
+	// prunePeriods removes the DASH Periods left without AdaptationSets
+	prunePeriods bool
+	// pruneVariants removes the HLS variants whose audio group was emptied
+	pruneVariants bool
+	// dashOnly skips the step when filtering HLS
+	dashOnly bool
+}
+
+// filterSteps is the registry of filter steps, in the order they are applied
+var filterSteps = []filterStep{
+	{
+		name: contentTypeFilter,
+		enabled: func(filters *parsers.MediaFilters) bool {
+			return len(filters.ContentTypes) > 0
+		},
+		remove:       removeContentType,
+		prunePeriods: true,
+		dashOnly:     true,
+	},
+	{
+		name: bitrateFilter,
+		enabled: func(filters *parsers.MediaFilters) bool {
+			return filters.Videos.Bitrate != nil || filters.Audios.Bitrate != nil
+		},
+		remove: removeBitrate,
+	},
+	{
+		name: videoCodecFilter,
+		enabled: func(filters *parsers.MediaFilters) bool {
+			return filters.Videos.Codecs != nil
+		},
+		remove: func(filters *parsers.MediaFilters, r Rendition) bool {
+			return matchCodecs(r.codecs(videoContentType), filters.Videos.Codecs)
+		},
+	},
+	{
+		name: codecProfileFilter,
+		enabled: func(filters *parsers.MediaFilters) bool {
+			return len(filters.Videos.CodecConstraints) > 0
+		},
+		remove: func(filters *parsers.MediaFilters, r Rendition) bool {
+			for _, codec := range r.codecs(videoContentType) {
+				for _, c := range filters.Videos.CodecConstraints {
+					if !c.Allows(codec) {
+						return true
+					}
+				}
+			}
+			return false
+		},
+	},
+	{
+		name: audioCodecFilter,
+		enabled: func(filters *parsers.MediaFilters) bool {
+			return filters.Audios.Codecs != nil
+		},
+		remove: func(filters *parsers.MediaFilters, r Rendition) bool {
+			return matchCodecs(r.codecs(audioContentType), filters.Audios.Codecs)
+		},
+	},
+	{
+		name: captionCodecFilter,
+		enabled: func(filters *parsers.MediaFilters) bool {
+			return filters.Captions.Codecs != nil
+		},
+		remove: func(filters *parsers.MediaFilters, r Rendition) bool {
+			return matchCodecs(r.codecs(captionContentType), filters.Captions.Codecs)
+		},
+	},
+	{
+		name: frameRateFilter,
+		enabled: func(filters *parsers.MediaFilters) bool {
+			return filters.FrameRate != nil || filters.FrameRateRange != nil
+		},
+		remove: removeFrameRate,
+	},
+	{
+		name: videoRangeFilter,
+		enabled: func(filters *parsers.MediaFilters) bool {
+			return filters.VideoRanges != nil
+		},
+		remove: func(filters *parsers.MediaFilters, r Rendition) bool {
+			return r.VideoRange != "" && matchFold(r.VideoRange, filters.VideoRanges)
+		},
+	},
+	{
+		name: languageFilter,
+		enabled: func(filters *parsers.MediaFilters) bool {
+			return filters.Audios.Language != nil || filters.Captions.Language != nil
+		},
+		remove: removeLanguage,
+	},
+	{
+		name: forcedFilter,
+		enabled: func(filters *parsers.MediaFilters) bool {
+			return filters.Captions.Forced != ""
+		},
+		remove: removeForced,
+	},
+	{
+		name: instreamIDFilter,
+		enabled: func(filters *parsers.MediaFilters) bool {
+			return len(filters.Captions.InstreamIDs) > 0
+		},
+		remove: func(filters *parsers.MediaFilters, r Rendition) bool {
+			return r.InstreamID != "" && matchFold(r.InstreamID, filters.Captions.InstreamIDs)
+		},
+	},
+	{
+		name: roleFilter,
+		enabled: func(filters *parsers.MediaFilters) bool {
+			return filters.Videos.Roles != nil || filters.Audios.Roles != nil || filters.Captions.Roles != nil
+		},
+		remove: removeRole,
+	},
+	{
+		name: channelsFilter,
+		enabled: func(filters *parsers.MediaFilters) bool {
+			return filters.Audios.MaxChannels > 0
+		},
+		remove: func(filters *parsers.MediaFilters, r Rendition) bool {
+			return r.Type == audioContentType && r.Channels > filters.Audios.MaxChannels
+		},
+		pruneVariants: true,
+	},
+	{
+		name: expressionFilter,
+		enabled: func(filters *parsers.MediaFilters) bool {
+			return len(filters.Expressions) > 0
+		},
+		remove: func(filters *parsers.MediaFilters, r Rendition) bool {
+			for _, e := range filters.Expressions {
+				if e.Match(r) {
+					return true
+				}
+			}
+			return false
+		},
+	},
+}
+
+// enabledSteps returns the registered steps of the protocol with something to filter
+func enabledSteps(filters *parsers.MediaFilters, protocol parsers.Protocol) []filterStep {
+	var steps []filterStep
+	for _, step := range filterSteps {
+		if step.dashOnly && protocol != parsers.ProtocolDASH {
+			continue
+		}
+
+		if step.enabled(filters) {
+			steps = append(steps, step)
+		}
+	}
+
+	return steps
+}
+
+// Returns true if the rendition content type is filtered
+func removeContentType(filters *parsers.MediaFilters, r Rendition) bool {
+	for _, ct := range filters.ContentTypes {
+		if r.Type != "" && ContentType(ct) == r.Type {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns true if the rendition bandwidth is out of range for any of its content types
+func removeBitrate(filters *parsers.MediaFilters, r Rendition) bool {
+	if r.Bandwidth == 0 {
+		return false
+	}
+
+	for _, ct := range r.contentTypes() {
+		var bitrate *parsers.Bitrate
+		switch ct {
+		case videoContentType:
+			bitrate = filters.Videos.Bitrate
+		case audioContentType:
+			bitrate = filters.Audios.Bitrate
+		}
+
+		if bitrate != nil && !inRange(bitrate.Min, bitrate.Max, r.Bandwidth) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns true if the rendition language is filtered for its content type
+func removeLanguage(filters *parsers.MediaFilters, r Rendition) bool {
+	var langs []string
+	switch r.Type {
+	case audioContentType:
+		langs = filters.Audios.Language
+	case captionContentType:
+		langs = filters.Captions.Language
+	}
+
+	return r.Language != "" && matchLanguage(r.Language, langs)
+}
+
+// Returns true if any of the rendition roles is filtered for its content type.
+// HLS variants carry no role and are kept
+func removeRole(filters *parsers.MediaFilters, r Rendition) bool {
+	var roles []string
+	switch r.Type {
+	case videoContentType:
+		roles = filters.Videos.Roles
+	case audioContentType:
+		roles = filters.Audios.Roles
+	case captionContentType:
+		roles = filters.Captions.Roles
+	}
+
+	for _, role := range r.Roles {
+		if matchFold(role, roles) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns true if the subtitles are forced and forced subtitles are dropped, or
+// if they are not forced and only forced subtitles are kept. Closed captions are
+// never forced and are kept
+func removeForced(filters *parsers.MediaFilters, r Rendition) bool {
+	if r.Type != captionContentType || r.InstreamID != "" {
+		return false
+	}
+
+	switch filters.Captions.Forced {
+	case parsers.ForcedDrop:
+		return r.Forced
+	case parsers.ForcedOnly:
+		return !r.Forced
+	}
+
+	return false
+}
+
+func matchCodecs(codecs []string, filtered []string) bool {
+	for _, codec := range codecs {
+		for _, f := range filtered {
+			if ValidCodecs(codec, CodecFilterID(f)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchFold returns true if the value equals any of the given values, ignoring case
+func matchFold(v string, values []string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns true if the rendition frame rate matches a filtered frame rate or
+// is out of the frame rate range. Renditions without a frame rate are kept
+func removeFrameRate(filters *parsers.MediaFilters, r Rendition) bool {
+	fps, ok := parsers.ParseFrameRate(r.FrameRate)
+	if !ok {
+		return false
+	}
+
+	if filters.FrameRateRange != nil && !filters.FrameRateRange.Contains(fps) {
+		return true
+	}
+
+	return matchFPS(fps, filters.FrameRate)
+}
+
+// matchFPS returns true if the frame rate equals any of the given ones,
+// whether written as decimals or ratios
+func matchFPS(fps float64, framerates []string) bool {
+	for _, fr := range framerates {
+		if f, ok := parsers.ParseFrameRate(fr); ok && parsers.EqualFrameRates(f, fps) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,221 @@
+package filters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cbsinteractive/bakery/config"
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafov/m3u8"
+	"github.com/zencoder/go-dash/mpd"
+)
+
+func TestFilterSteps_Remove(t *testing.T) {
+	hlsVariant := newVariantRendition(&m3u8.Variant{
+		VariantParams: m3u8.VariantParams{
+			Bandwidth:  4000,
+			Codecs:     "hvc1.2.4.L93.90,ec-3",
+			Resolution: "1920x1080",
+			FrameRate:  29.97,
+		},
+	})
+
+	hlsAlternative := newAlternativeRendition(&m3u8.Alternative{
+		Type:     "AUDIO",
+		Language: "en",
+	}, "16/JOC")
+
+	dashVideo := newRepresentationRendition(
+		&mpd.AdaptationSet{CommonAttributesAndElements: mpd.CommonAttributesAndElements{FrameRate: strptr("29.970")}, ContentType: strptr("video")},
+		&mpd.Representation{Bandwidth: int64ptr(4000), Codecs: strptr("hvc1.2.4.L93.90")},
+	)
+
+	dashAudio := newRepresentationRendition(
+		&mpd.AdaptationSet{ContentType: strptr("audio"), Lang: strptr("en")},
+		&mpd.Representation{Bandwidth: int64ptr(96000), Codecs: strptr("ec-3")},
+	)
+
+	hlsForcedSubtitles := newAlternativeRendition(&m3u8.Alternative{
+		Type:   "SUBTITLES",
+		Forced: "YES",
+	}, "")
+
+	hlsClosedCaptions := newAlternativeRendition(&m3u8.Alternative{
+		Type:       "CLOSED-CAPTIONS",
+		InstreamID: "CC1",
+	}, "")
+
+	tests := []struct {
+		name       string
+		filters    *parsers.MediaFilters
+		protocol   parsers.Protocol
+		rendition  Rendition
+		expectStep FilterName
+	}{
+		{
+			name:       "when a video codec filter matches an hls variant, expect it removed",
+			filters:    &parsers.MediaFilters{Videos: parsers.NestedFilters{Codecs: []string{"hvc"}}},
+			rendition:  hlsVariant,
+			expectStep: videoCodecFilter,
+		},
+		{
+			name:       "when a video codec filter matches a dash representation, expect it removed",
+			filters:    &parsers.MediaFilters{Videos: parsers.NestedFilters{Codecs: []string{"hvc"}}},
+			rendition:  dashVideo,
+			expectStep: videoCodecFilter,
+		},
+		{
+			name:       "when an audio codec filter matches an hls variant, expect it removed",
+			filters:    &parsers.MediaFilters{Audios: parsers.NestedFilters{Codecs: []string{"ec-3"}}},
+			rendition:  hlsVariant,
+			expectStep: audioCodecFilter,
+		},
+		{
+			name:      "when an audio codec filter is applied to a dash video representation, expect it kept",
+			filters:   &parsers.MediaFilters{Audios: parsers.NestedFilters{Codecs: []string{"ec-3"}}},
+			rendition: dashVideo,
+		},
+		{
+			name:       "when the bandwidth is out of the video range, expect the hls variant removed",
+			filters:    &parsers.MediaFilters{Videos: parsers.NestedFilters{Bitrate: &parsers.Bitrate{Min: 0, Max: 3000}}},
+			rendition:  hlsVariant,
+			expectStep: bitrateFilter,
+		},
+		{
+			name:      "when only a video range is set, expect the dash audio representation kept",
+			filters:   &parsers.MediaFilters{Videos: parsers.NestedFilters{Bitrate: &parsers.Bitrate{Min: 0, Max: 3000}}},
+			rendition: dashAudio,
+		},
+		{
+			name:      "when an hls alternative has no bandwidth, expect the bitrate filter to keep it",
+			filters:   &parsers.MediaFilters{Audios: parsers.NestedFilters{Bitrate: &parsers.Bitrate{Min: 100, Max: 3000}}},
+			rendition: hlsAlternative,
+		},
+		{
+			name:       "when the frame rate matches an hls variant, expect it removed",
+			filters:    &parsers.MediaFilters{FrameRate: []string{"29.970"}},
+			rendition:  hlsVariant,
+			expectStep: frameRateFilter,
+		},
+		{
+			name:       "when the frame rate inherited from the adaptation set matches, expect the representation removed",
+			filters:    &parsers.MediaFilters{FrameRate: []string{"29.970"}},
+			rendition:  dashVideo,
+			expectStep: frameRateFilter,
+		},
+		{
+			name:       "when the language matches an hls alternative regardless of case, expect it removed",
+			filters:    &parsers.MediaFilters{Audios: parsers.NestedFilters{Language: []string{"EN"}}},
+			rendition:  hlsAlternative,
+			expectStep: languageFilter,
+		},
+		{
+			name:       "when the language matches a dash audio representation, expect it removed",
+			filters:    &parsers.MediaFilters{Audios: parsers.NestedFilters{Language: []string{"en"}}},
+			rendition:  dashAudio,
+			expectStep: languageFilter,
+		},
+		{
+			name:      "when a caption language is filtered, expect audio renditions kept",
+			filters:   &parsers.MediaFilters{Captions: parsers.NestedFilters{Language: []string{"en"}}},
+			rendition: dashAudio,
+		},
+		{
+			name:       "when an hls alternative has more channels than allowed, expect it removed",
+			filters:    &parsers.MediaFilters{Audios: parsers.NestedFilters{MaxChannels: 6}},
+			rendition:  hlsAlternative,
+			expectStep: channelsFilter,
+		},
+		{
+			name:      "when a dash audio representation does not signal its channels, expect it kept",
+			filters:   &parsers.MediaFilters{Audios: parsers.NestedFilters{MaxChannels: 2}},
+			rendition: dashAudio,
+		},
+		{
+			name:       "when forced subtitles are dropped, expect forced hls subtitles removed",
+			filters:    &parsers.MediaFilters{Captions: parsers.NestedFilters{Forced: parsers.ForcedDrop}},
+			rendition:  hlsForcedSubtitles,
+			expectStep: forcedFilter,
+		},
+		{
+			name:      "when only forced subtitles are kept, expect closed captions kept",
+			filters:   &parsers.MediaFilters{Captions: parsers.NestedFilters{Forced: parsers.ForcedOnly}},
+			rendition: hlsClosedCaptions,
+		},
+		{
+			name:       "when the instream id of closed captions is filtered, expect them removed",
+			filters:    &parsers.MediaFilters{Captions: parsers.NestedFilters{InstreamIDs: []string{"CC1"}}},
+			rendition:  hlsClosedCaptions,
+			expectStep: instreamIDFilter,
+		},
+		{
+			name:      "when a role is filtered, expect hls alternatives without characteristics kept",
+			filters:   &parsers.MediaFilters{Audios: parsers.NestedFilters{Roles: []string{"description"}}},
+			rendition: hlsAlternative,
+		},
+		{
+			name:      "when a role is filtered, expect hls variants kept as they carry no role",
+			filters:   &parsers.MediaFilters{Videos: parsers.NestedFilters{Roles: []string{"main"}}},
+			rendition: hlsVariant,
+		},
+		{
+			name:      "when the content type matches an hls alternative, expect it kept as hls ignores content types",
+			filters:   &parsers.MediaFilters{ContentTypes: []string{"audio"}},
+			protocol:  parsers.ProtocolHLS,
+			rendition: hlsAlternative,
+		},
+		{
+			name:       "when the content type matches a dash representation, expect it removed",
+			filters:    &parsers.MediaFilters{ContentTypes: []string{"audio"}},
+			rendition:  dashAudio,
+			expectStep: contentTypeFilter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protocol := tt.protocol
+			if protocol == "" {
+				protocol = parsers.ProtocolDASH
+			}
+
+			var got FilterName
+			for _, step := range enabledSteps(tt.filters, protocol) {
+				if step.remove(tt.filters, tt.rendition) {
+					got = step.name
+					break
+				}
+			}
+
+			if got != tt.expectStep {
+				t.Errorf("Wrong step removing the rendition\ngot %q\nexpected: %q", got, tt.expectStep)
+			}
+		})
+	}
+}
+
+func TestHLSFilter_FilterContent_ContentTypeFilter(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/audio_en.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="English",DEFAULT=YES,LANGUAGE="en",URI="http://existing.base/uri/subs_en.m3u8"
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=1000,CODECS="avc1.77.30,mp4a.40.2",AUDIO="aac",SUBTITLES="subs"
+http://existing.base/uri/link_1.m3u8
+`
+
+	// content types are only supported for DASH, HLS masters are left untouched
+	filter := NewHLSFilter("http://existing.base/uri/master.m3u8", manifest, config.Config{})
+	got, err := filter.FilterContent(context.Background(), &parsers.MediaFilters{ContentTypes: []string{"audio", "text"}})
+	if err != nil {
+		t.Fatalf("FilterContent() didnt expect an error to be returned, got: %v", err)
+	}
+
+	if !cmp.Equal(got, manifest) {
+		t.Errorf("Wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v", got, manifest, cmp.Diff(got, manifest))
+	}
+}
+
+func int64ptr(i int64) *int64 {
+	return &i
+}
@@ -0,0 +1,139 @@
+package filters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/grafov/m3u8"
+	"github.com/zencoder/go-dash/mpd"
+)
+
+// Severity ranks the issues found in a manifest
+type Severity string
+
+// Issue severities. Players reject manifests with errors
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue describes a problem found in a manifest
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// ValidateManifest checks the structural invariants players rely on, such as a
+// master playlist holding variants or each DASH Period holding AdaptationSets,
+// and returns the issues found in the filtered manifest
+func ValidateManifest(filters *parsers.MediaFilters, manifest string) []Issue {
+	switch filters.Protocol {
+	case parsers.ProtocolHLS:
+		return validateHLS(filters, manifest)
+	case parsers.ProtocolDASH:
+		return validateDASH(manifest)
+	}
+
+	return nil
+}
+
+// HasErrors returns true if any of the issues is an error
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RemovedBy returns the filters which removed renditions, in the order they
+// first removed one
+func RemovedBy(decisions []Decision) []FilterName {
+	var names []FilterName
+	seen := map[FilterName]struct{}{}
+	for _, d := range decisions {
+		if _, found := seen[d.Filter]; d.Kept || d.Filter == "" || found {
+			continue
+		}
+		seen[d.Filter] = struct{}{}
+		names = append(names, d.Filter)
+	}
+
+	return names
+}
+
+func validateHLS(filters *parsers.MediaFilters, manifest string) []Issue {
+	if strings.TrimSpace(manifest) == EmptyHLSManifestContent {
+		return []Issue{{SeverityError, "playlist is empty"}}
+	}
+
+	p, listType, err := m3u8.DecodeFrom(strings.NewReader(manifest), true)
+	if err != nil {
+		return []Issue{{SeverityError, fmt.Sprintf("playlist cannot be decoded: %v", err)}}
+	}
+
+	// a master playlist without variants decodes as a media playlist, only the
+	// latter carrying EXT-X-TARGETDURATION
+	if listType == m3u8.MEDIA && strings.Contains(manifest, "#EXT-X-TARGETDURATION") {
+		// trimmed playlists are legitimately left without segments out of range
+		if filters.Trim == nil && segmentCount(p.(*m3u8.MediaPlaylist)) == 0 {
+			return []Issue{{SeverityError, "media playlist has no segments"}}
+		}
+		return nil
+	}
+
+	if listType == m3u8.MEDIA {
+		return []Issue{{SeverityError, "master playlist has no variants"}}
+	}
+
+	for _, v := range p.(*m3u8.MasterPlaylist).Variants {
+		if v != nil && !v.Iframe {
+			return nil
+		}
+	}
+
+	return []Issue{{SeverityError, "master playlist has no variants"}}
+}
+
+func segmentCount(playlist *m3u8.MediaPlaylist) int {
+	var count int
+	for _, segment := range playlist.Segments {
+		if segment != nil {
+			count++
+		}
+	}
+
+	return count
+}
+
+func validateDASH(manifest string) []Issue {
+	m, err := mpd.ReadFromString(manifest)
+	if err != nil {
+		return []Issue{{SeverityError, fmt.Sprintf("MPD cannot be decoded: %v", err)}}
+	}
+
+	if len(m.Periods) == 0 {
+		return []Issue{{SeverityError, "MPD has no Periods"}}
+	}
+
+	var issues []Issue
+	for i, period := range m.Periods {
+		if len(period.AdaptationSets) == 0 {
+			issues = append(issues, Issue{SeverityError, fmt.Sprintf("Period %v has no AdaptationSets", periodID(period, i))})
+		}
+	}
+
+	return issues
+}
+
+// periodID returns the id of the Period, or its index when it has none
+func periodID(period *mpd.Period, i int) string {
+	if period.ID != "" {
+		return period.ID
+	}
+
+	return fmt.Sprintf("at index %d", i)
+}
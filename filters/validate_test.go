@@ -0,0 +1,107 @@
+package filters
+
+import (
+	"testing"
+
+	"github.com/cbsinteractive/bakery/parsers"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		filters  *parsers.MediaFilters
+		manifest string
+		expect   []Issue
+	}{
+		{
+			name:    "when an hls master playlist has variants, expect no issues",
+			filters: &parsers.MediaFilters{Protocol: parsers.ProtocolHLS},
+			manifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a"
+link_1.m3u8
+`,
+		},
+		{
+			name:    "when an hls master playlist only has i-frame variants, expect an error",
+			filters: &parsers.MediaFilters{Protocol: parsers.ProtocolHLS},
+			manifest: `#EXTM3U
+#EXT-X-VERSION:4
+#EXT-X-I-FRAME-STREAM-INF:BANDWIDTH=1000,CODECS="avc1.77.30",URI="iframe.m3u8"
+`,
+			expect: []Issue{{SeverityError, "master playlist has no variants"}},
+		},
+		{
+			name:     "when an hls playlist is empty, expect an error",
+			filters:  &parsers.MediaFilters{Protocol: parsers.ProtocolHLS},
+			manifest: EmptyHLSManifestContent,
+			expect:   []Issue{{SeverityError, "playlist is empty"}},
+		},
+		{
+			name:    "when an hls media playlist has no segments, expect an error",
+			filters: &parsers.MediaFilters{Protocol: parsers.ProtocolHLS},
+			manifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:6
+#EXT-X-ENDLIST
+`,
+			expect: []Issue{{SeverityError, "media playlist has no segments"}},
+		},
+		{
+			name:    "when a trimmed hls media playlist has no segments, expect no issues",
+			filters: &parsers.MediaFilters{Protocol: parsers.ProtocolHLS, Trim: &parsers.Trim{Start: 0, End: 10}},
+			manifest: `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:6
+#EXT-X-ENDLIST
+`,
+		},
+		{
+			name:    "when a dash period has no adaptation sets, expect an error",
+			filters: &parsers.MediaFilters{Protocol: parsers.ProtocolDASH},
+			manifest: `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S">
+  <Period id="0">
+    <AdaptationSet id="0" contentType="video">
+      <Representation bandwidth="1000" codecs="avc1.640029" id="0"></Representation>
+    </AdaptationSet>
+  </Period>
+  <Period id="1"></Period>
+</MPD>
+`,
+			expect: []Issue{{SeverityError, "Period 1 has no AdaptationSets"}},
+		},
+		{
+			name:    "when a dash manifest has no periods, expect an error",
+			filters: &parsers.MediaFilters{Protocol: parsers.ProtocolDASH},
+			manifest: `<?xml version="1.0" encoding="UTF-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-on-demand:2011" type="static" mediaPresentationDuration="PT6M16S" minBufferTime="PT1.97S"></MPD>
+`,
+			expect: []Issue{{SeverityError, "MPD has no Periods"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateManifest(tt.filters, tt.manifest)
+			if !cmp.Equal(got, tt.expect) {
+				t.Errorf("ValidateManifest() wrong issues returned\ngot %v\nexpected: %v\ndiff: %v", got, tt.expect, cmp.Diff(got, tt.expect))
+			}
+		})
+	}
+}
+
+func TestRemovedBy(t *testing.T) {
+	decisions := []Decision{
+		{Kept: true},
+		{Filter: bitrateFilter},
+		{Filter: languageFilter},
+		{Filter: bitrateFilter},
+	}
+
+	expect := []FilterName{bitrateFilter, languageFilter}
+	if got := RemovedBy(decisions); !cmp.Equal(got, expect) {
+		t.Errorf("RemovedBy() = %v, expected %v", got, expect)
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/cbsinteractive/bakery/filters"
+	"github.com/cbsinteractive/bakery/parsers"
+)
+
+const explainPrefix = "/explain"
+
+// explainResponse holds the parsed filters, the resolved origin and the
+// decision taken for every rendition of the origin manifest
+type explainResponse struct {
+	Filters   *parsers.MediaFilters `json:"filters"`
+	OriginURL string                `json:"originURL"`
+	Decisions []filters.Decision    `json:"decisions"`
+}
+
+// explainPath returns the request path without the explain prefix and
+// whether the request asked for an explanation via the prefix or the
+// explain query parameter
+func explainPath(r *http.Request) (string, bool) {
+	p := r.URL.Path
+	if strings.HasPrefix(p, explainPrefix+"/") {
+		return strings.TrimPrefix(p, explainPrefix), true
+	}
+
+	switch r.URL.Query().Get("explain") {
+	case "1", "true":
+		return p, true
+	}
+
+	return p, false
+}
+
+// writeExplanation writes the decisions recorded by the filter as json
+func writeExplanation(w http.ResponseWriter, mf *parsers.MediaFilters, originURL string, f filters.Filter) {
+	resp := explainResponse{
+		Filters:   mf,
+		OriginURL: originURL,
+		Decisions: []filters.Decision{},
+	}
+
+	if e, ok := f.(filters.Explainer); ok && e.Decisions() != nil {
+		resp.Decisions = e.Decisions()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cbsinteractive/bakery/config"
+	"github.com/cbsinteractive/bakery/parsers"
+)
+
+// maxManifestBodySize limits the size of manifests posted for filtering
+const maxManifestBodySize = 10 << 20
+
+// filterRequest is the json body accepted by the filter handler. Filters
+// can either be set as a Bakery filter path or as json matching MediaFilters
+type filterRequest struct {
+	Manifest   string           `json:"manifest"`
+	BaseURL    string           `json:"baseURL"`
+	Protocol   parsers.Protocol `json:"protocol"`
+	FilterPath string           `json:"filterPath"`
+	Filters    json.RawMessage  `json:"filters"`
+}
+
+// LoadFilterHandler loads the handler applying filters to a manifest supplied
+// in the request body. A json body is decoded as a filterRequest, any other body
+// is treated as the raw manifest with the base url and filter path set through
+// the baseURL and filters query parameters
+func LoadFilterHandler(c config.Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			e := NewErrorResponse("method not allowed", fmt.Errorf("method: %v is not supported", r.Method))
+			e.HandleError(r.Context(), w, http.StatusMethodNotAllowed)
+			return
+		}
+
+		req, err := decodeFilterRequest(w, r)
+		if err != nil {
+			e := NewErrorResponse("failed decoding request", err)
+			e.HandleError(r.Context(), w, http.StatusBadRequest)
+			return
+		}
+
+		mediaFilters, err := req.mediaFilters()
+		if err != nil {
+			e := NewErrorResponse("failed parsing filters", err)
+			e.HandleError(r.Context(), w, http.StatusBadRequest)
+			return
+		}
+
+		f, contentType := newFilter(mediaFilters.Protocol, req.BaseURL, req.Manifest, c)
+		filteredManifest, err := f.FilterContent(r.Context(), mediaFilters)
+		if err != nil {
+			e := NewErrorResponse("failed to filter manifest", err)
+			e.HandleError(r.Context(), w, http.StatusInternalServerError)
+			return
+		}
+
+		filteredManifest, ok := validateManifest(w, r, c, mediaFilters, f, req.BaseURL, req.Manifest, filteredManifest)
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		fmt.Fprint(w, filteredManifest)
+	})
+}
+
+func decodeFilterRequest(w http.ResponseWriter, r *http.Request) (filterRequest, error) {
+	var req filterRequest
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxManifestBodySize))
+	if err != nil {
+		return req, fmt.Errorf("reading body: %w", err)
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.Unmarshal(body, &req); err != nil {
+			return req, fmt.Errorf("decoding body: %w", err)
+		}
+	} else {
+		query := r.URL.Query()
+		req.Manifest = string(body)
+		req.BaseURL = query.Get("baseURL")
+		req.Protocol = parsers.Protocol(query.Get("protocol"))
+		req.FilterPath = query.Get("filters")
+	}
+
+	if req.Manifest == "" {
+		return req, fmt.Errorf("manifest: manifest body is empty")
+	}
+
+	u, err := url.Parse(req.BaseURL)
+	if err != nil || !u.IsAbs() {
+		return req, fmt.Errorf("base url: an absolute base url must be provided, got %q", req.BaseURL)
+	}
+
+	if req.Protocol == "" {
+		req.Protocol = detectProtocol(req.BaseURL, req.Manifest)
+	}
+
+	switch req.Protocol {
+	case parsers.ProtocolHLS, parsers.ProtocolDASH, parsers.ProtocolVTT:
+	default:
+		return req, fmt.Errorf("protocol: unsupported protocol %q", req.Protocol)
+	}
+
+	return req, nil
+}
+
+// mediaFilters parses the filters of the request for its protocol
+func (req filterRequest) mediaFilters() (*parsers.MediaFilters, error) {
+	if len(req.Filters) > 0 {
+		return parsers.JSONParse(req.Filters, req.Protocol)
+	}
+
+	return parsers.FilterParse(req.FilterPath, req.Protocol)
+}
+
+// detectProtocol returns the protocol of the base url, falling back
+// to inspecting the manifest content
+func detectProtocol(baseURL, manifest string) parsers.Protocol {
+	if u, err := url.Parse(baseURL); err == nil {
+		if p, found := parsers.ProtocolFromPath(u.Path); found {
+			return p
+		}
+	}
+
+	p, _ := parsers.ProtocolFromContent(manifest)
+	return p
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	test "github.com/cbsinteractive/bakery/tests"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHandler_Filter(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a"
+link_1.m3u8
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=8000,CODECS="avc1.77.30,mp4a"
+link_2.m3u8
+`
+
+	filteredManifest := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS="avc1.77.30,mp4a"
+http://existing.base/uri/link_1.m3u8
+`
+
+	jsonBody := func(filters string) string {
+		return `{"manifest":"#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=2000,CODECS=\"avc1.77.30,mp4a\"\nlink_1.m3u8\n#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=8000,CODECS=\"avc1.77.30,mp4a\"\nlink_2.m3u8\n",` +
+			`"baseURL":"http://existing.base/uri/master.m3u8",` + filters + `}`
+	}
+
+	tests := []struct {
+		name              string
+		method            string
+		url               string
+		contentType       string
+		body              string
+		expectStatus      int
+		expectContentType string
+		expectManifest    string
+	}{
+		{
+			name:              "when raw manifest is posted with filter path query, expect filtered manifest",
+			method:            http.MethodPost,
+			url:               "/filter?baseURL=http://existing.base/uri/master.m3u8&filters=/b(0,5000)/",
+			body:              manifest,
+			expectStatus:      200,
+			expectContentType: "application/x-mpegURL",
+			expectManifest:    filteredManifest,
+		},
+		{
+			name:              "when json body carries a filter path, expect filtered manifest",
+			method:            http.MethodPost,
+			url:               "/filter",
+			contentType:       "application/json",
+			body:              jsonBody(`"filterPath":"/b(0,5000)/"`),
+			expectStatus:      200,
+			expectContentType: "application/x-mpegURL",
+			expectManifest:    filteredManifest,
+		},
+		{
+			name:              "when json body carries json filters, expect filtered manifest",
+			method:            http.MethodPost,
+			url:               "/filter",
+			contentType:       "application/json",
+			body:              jsonBody(`"filters":{"Bitrate":{"Max":5000}}`),
+			expectStatus:      200,
+			expectContentType: "application/x-mpegURL",
+			expectManifest:    filteredManifest,
+		},
+		{
+			name:         "when base url is missing, expect 400",
+			method:       http.MethodPost,
+			url:          "/filter?filters=/b(0,5000)/",
+			body:         manifest,
+			expectStatus: 400,
+		},
+		{
+			name:         "when filter path is invalid, expect 400",
+			method:       http.MethodPost,
+			url:          "/filter?baseURL=http://existing.base/uri/master.m3u8&filters=/b(5000,0)/",
+			body:         manifest,
+			expectStatus: 400,
+		},
+		{
+			name:         "when json filters carry an unsupported codec, expect 400",
+			method:       http.MethodPost,
+			url:          "/filter",
+			contentType:  "application/json",
+			body:         jsonBody(`"filters":{"Videos":{"Codecs":["h264"]}}`),
+			expectStatus: 400,
+		},
+		{
+			name:         "when json filters carry an inverted bitrate range, expect 400",
+			method:       http.MethodPost,
+			url:          "/filter",
+			contentType:  "application/json",
+			body:         jsonBody(`"filters":{"Bitrate":{"Min":5000,"Max":1000}}`),
+			expectStatus: 400,
+		},
+		{
+			name:         "when method is not POST, expect 405",
+			method:       http.MethodGet,
+			url:          "/filter",
+			expectStatus: 405,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := testConfig(test.MockClient(default200Response("")))
+			handler := LoadFilterHandler(c)
+
+			req, err := http.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			if err != nil {
+				t.Fatalf("could not create request got error: %v", err)
+			}
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+
+			rec := getResponseRecorder()
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			if res.StatusCode != tc.expectStatus {
+				t.Fatalf("expected status %v; got %v", tc.expectStatus, res.StatusCode)
+			}
+
+			if tc.expectStatus != 200 {
+				return
+			}
+
+			if ct := res.Header.Get("Content-Type"); ct != tc.expectContentType {
+				t.Errorf("expected content type %v; got %v", tc.expectContentType, ct)
+			}
+
+			body, err := ioutil.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := string(body); !cmp.Equal(got, tc.expectManifest) {
+				t.Errorf("Wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v",
+					got, tc.expectManifest, cmp.Diff(got, tc.expectManifest))
+			}
+		})
+	}
+}
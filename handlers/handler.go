@@ -16,8 +16,11 @@ func LoadHandler(c config.Config) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 
+		urlPath, explain := explainPath(r)
+		urlPath, lint := lintPath(urlPath)
+
 		// parse all the filters from the URL
-		masterManifestPath, mediaFilters, err := parsers.URLParse(r.URL.Path)
+		masterManifestPath, mediaFilters, err := parsers.URLParse(urlPath)
 		if err != nil {
 			e := NewErrorResponse("failed parsing filters", err)
 			e.HandleError(r.Context(), w, http.StatusBadRequest)
@@ -61,20 +64,16 @@ func LoadHandler(c config.Config) http.Handler {
 			return
 		}
 
+		// report the issues of the origin manifest instead of filtering it
+		if lint {
+			writeLint(w, mediaFilters.Protocol, o.GetPlaybackURL(), contentInfo.Payload)
+			return
+		}
+
 		// create filter associated to the protocol and set
 		// response headers accordingly
-		var f filters.Filter
-		switch mediaFilters.Protocol {
-		case parsers.ProtocolHLS:
-			f = filters.NewHLSFilter(o.GetPlaybackURL(), contentInfo.Payload, c)
-			w.Header().Set("Content-Type", "application/x-mpegURL")
-		case parsers.ProtocolDASH:
-			f = filters.NewDASHFilter(o.GetPlaybackURL(), contentInfo.Payload, c)
-			w.Header().Set("Content-Type", "application/dash+xml")
-		case parsers.ProtocolVTT:
-			f = filters.NewVTTFilter(o.GetPlaybackURL(), contentInfo.Payload, c)
-			w.Header().Set("Content-Type", "text/vtt")
-		}
+		f, contentType := newFilter(mediaFilters.Protocol, o.GetPlaybackURL(), contentInfo.Payload, c)
+		w.Header().Set("Content-Type", contentType)
 
 		// apply the filters to the origin manifest
 		filteredManifest, err := f.FilterContent(r.Context(), mediaFilters)
@@ -84,6 +83,17 @@ func LoadHandler(c config.Config) http.Handler {
 			return
 		}
 
+		// report why each rendition was kept or removed instead of the manifest
+		if explain {
+			writeExplanation(w, mediaFilters, o.GetPlaybackURL(), f)
+			return
+		}
+
+		filteredManifest, ok := validateManifest(w, r, c, mediaFilters, f, o.GetPlaybackURL(), contentInfo.Payload, filteredManifest)
+		if !ok {
+			return
+		}
+
 		// set cache-control if serving hls media playlist
 		if maxAge := f.GetMaxAge(); maxAge != "" && maxAge != "0" {
 			w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%v", maxAge))
@@ -93,3 +103,16 @@ func LoadHandler(c config.Config) http.Handler {
 		fmt.Fprint(w, filteredManifest)
 	})
 }
+
+// newFilter creates the filter associated to the protocol along with
+// the content type of the manifests it produces
+func newFilter(p parsers.Protocol, originURL, content string, c config.Config) (filters.Filter, string) {
+	switch p {
+	case parsers.ProtocolDASH:
+		return filters.NewDASHFilter(originURL, content, c), "application/dash+xml"
+	case parsers.ProtocolVTT:
+		return filters.NewVTTFilter(originURL, content, c), "text/vtt"
+	}
+
+	return filters.NewHLSFilter(originURL, content, c), "application/x-mpegURL"
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -170,3 +171,76 @@ func TestHandler(t *testing.T) {
 		}
 	}
 }
+
+func TestHandler_Explain(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		expectExplain bool
+	}{
+		{
+			name:          "when explain query param is set, expect json explanation",
+			url:           "/b(0,5000)/origin/some/path/to/master.m3u8?explain=1",
+			expectExplain: true,
+		},
+		{
+			name:          "when explain prefix is set, expect json explanation",
+			url:           "/explain/b(0,5000)/origin/some/path/to/master.m3u8",
+			expectExplain: true,
+		},
+		{
+			name: "when explain is not requested, expect the filtered manifest",
+			url:  "/b(0,5000)/origin/some/path/to/master.m3u8",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := testConfig(test.MockClient(default200Response(getManifest())))
+			handler := LoadHandler(c)
+			req := getRequest(tc.url, t)
+			rec := getResponseRecorder()
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			if res.StatusCode != 200 {
+				t.Fatalf("expected status 200; got %v", res.StatusCode)
+			}
+
+			if !tc.expectExplain {
+				if ct := res.Header.Get("Content-Type"); ct != "application/x-mpegURL" {
+					t.Errorf("expected manifest content type; got %v", ct)
+				}
+				return
+			}
+
+			var got explainResponse
+			if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.OriginURL != "http://localhost:8080/origin/some/path/to/master.m3u8" {
+				t.Errorf("wrong origin url returned: %v", got.OriginURL)
+			}
+
+			if got.Filters.Videos.Bitrate == nil || got.Filters.Videos.Bitrate.Max != 5000 {
+				t.Errorf("expected parsed bitrate filter to be returned, got: %+v", got.Filters)
+			}
+
+			var kept, removed int
+			for _, d := range got.Decisions {
+				if d.Kept {
+					kept++
+					continue
+				}
+				removed++
+			}
+
+			if kept != 2 || removed != 3 {
+				t.Errorf("expected 2 kept and 3 removed variants; got %v kept and %v removed", kept, removed)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cbsinteractive/bakery/config"
+	"github.com/cbsinteractive/bakery/origin"
+)
+
+// Pinger is implemented by dependencies that can report whether they are reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingerFunc allows a plain function to be used as a Pinger
+type PingerFunc func(ctx context.Context) error
+
+// Ping calls f(ctx)
+func (f PingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+// ReadinessCheck is a named dependency evaluated by the readiness handler
+type ReadinessCheck struct {
+	Name string
+	Pinger
+}
+
+// readinessResponse is the body returned by the readiness handler
+type readinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// LoadHealthHandler loads the liveness handler. It only reports that the
+// process is up and serving requests
+func LoadHealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "ok")
+	})
+}
+
+// LoadReadinessHandler loads the readiness handler. The configuration is validated
+// and each check is pinged, any failure will return a 503
+func LoadReadinessHandler(c config.Config, checks ...ReadinessCheck) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := readinessResponse{
+			Status: "ready",
+			Checks: map[string]string{"config": "ok"},
+		}
+
+		if err := c.ValidateAuthHeader(); err != nil {
+			resp.Status = "unavailable"
+			resp.Checks["config"] = err.Error()
+		}
+
+		for _, check := range checks {
+			if err := check.Ping(r.Context()); err != nil {
+				resp.Status = "unavailable"
+				resp.Checks[check.Name] = err.Error()
+				continue
+			}
+			resp.Checks[check.Name] = "ok"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if resp.Status != "ready" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// ReadinessChecks returns the default checks for a given config. Propeller is
+// always checked while the origin is only probed when a probe url is configured
+func ReadinessChecks(c config.Config) []ReadinessCheck {
+	checks := []ReadinessCheck{
+		{Name: "propeller", Pinger: c.Propeller},
+	}
+
+	if c.ProbeURL != "" {
+		checks = append(checks, ReadinessCheck{Name: "origin", Pinger: originProbe(c)})
+	}
+
+	return checks
+}
+
+// originProbe fetches the configured probe url through the origin package
+func originProbe(c config.Config) Pinger {
+	return PingerFunc(func(ctx context.Context) error {
+		o, err := origin.NewDefaultOrigin(c.OriginHost, c.ProbeURL)
+		if err != nil {
+			return fmt.Errorf("configuring origin probe: %w", err)
+		}
+
+		contentInfo, err := o.FetchOriginContent(ctx, c.Client)
+		if err != nil {
+			return err
+		}
+
+		if contentInfo.Status/100 > 3 {
+			return fmt.Errorf("probing origin: returning http status of %v", contentInfo.Status)
+		}
+
+		return nil
+	})
+}
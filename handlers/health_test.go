@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	test "github.com/cbsinteractive/bakery/tests"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHandler_Health(t *testing.T) {
+	handler := LoadHealthHandler()
+	req := getRequest("/healthz", t)
+	rec := getResponseRecorder()
+	handler.ServeHTTP(rec, req)
+
+	res := rec.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200; got %v", res.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(body); got != "ok" {
+		t.Errorf("Wrong body returned\ngot %v\nexpected: ok", got)
+	}
+}
+
+func TestHandler_Readiness(t *testing.T) {
+	okPinger := PingerFunc(func(context.Context) error { return nil })
+	failPinger := PingerFunc(func(context.Context) error { return errors.New("connection refused") })
+
+	tests := []struct {
+		name         string
+		originToken  string
+		checks       []ReadinessCheck
+		expectStatus int
+		expectBody   readinessResponse
+	}{
+		{
+			name:         "when config is valid and all checks pass, expect 200",
+			originToken:  "authenticate-me",
+			checks:       []ReadinessCheck{{Name: "propeller", Pinger: okPinger}},
+			expectStatus: http.StatusOK,
+			expectBody: readinessResponse{
+				Status: "ready",
+				Checks: map[string]string{"config": "ok", "propeller": "ok"},
+			},
+		},
+		{
+			name:        "when a check fails, expect 503 with the failing check reported",
+			originToken: "authenticate-me",
+			checks: []ReadinessCheck{
+				{Name: "propeller", Pinger: failPinger},
+				{Name: "origin", Pinger: okPinger},
+			},
+			expectStatus: http.StatusServiceUnavailable,
+			expectBody: readinessResponse{
+				Status: "unavailable",
+				Checks: map[string]string{"config": "ok", "propeller": "connection refused", "origin": "ok"},
+			},
+		},
+		{
+			name:         "when auth is not configured, expect 503 with the config error reported",
+			checks:       []ReadinessCheck{{Name: "propeller", Pinger: okPinger}},
+			expectStatus: http.StatusServiceUnavailable,
+			expectBody: readinessResponse{
+				Status: "unavailable",
+				Checks: map[string]string{
+					"config":    "Authentication not set.\nKey: x-bakery-origin-token,Value: ",
+					"propeller": "ok",
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := testConfig(test.MockClient(default200Response("")))
+			c.OriginToken = tc.originToken
+
+			handler := LoadReadinessHandler(c, tc.checks...)
+			req := getRequest("/readyz", t)
+			rec := getResponseRecorder()
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			if res.StatusCode != tc.expectStatus {
+				t.Errorf("expected status %v; got %v", tc.expectStatus, res.StatusCode)
+			}
+
+			var got readinessResponse
+			if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(got, tc.expectBody) {
+				t.Errorf("Wrong body returned\ngot %v\nexpected: %v\ndiff: %v",
+					got, tc.expectBody, cmp.Diff(got, tc.expectBody))
+			}
+		})
+	}
+}
+
+func TestHandler_ReadinessChecks(t *testing.T) {
+	c := testConfig(test.MockClient(default404Response("")))
+
+	if got := len(ReadinessChecks(c)); got != 1 {
+		t.Errorf("expected only the propeller check when no probe url is set; got %v checks", got)
+	}
+
+	c.ProbeURL = "/probe/master.m3u8"
+	checks := ReadinessChecks(c)
+	if got := len(checks); got != 2 {
+		t.Fatalf("expected propeller and origin checks when probe url is set; got %v checks", got)
+	}
+
+	if err := checks[1].Ping(context.Background()); err == nil {
+		t.Error("expected origin probe to fail when origin returns 404, got nil")
+	}
+}
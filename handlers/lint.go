@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/cbsinteractive/bakery/filters"
+	"github.com/cbsinteractive/bakery/parsers"
+)
+
+const lintPrefix = "/lint"
+
+// lintResponse holds the resolved origin and the issues found in the origin
+// manifest, before any filter is applied
+type lintResponse struct {
+	Protocol  parsers.Protocol `json:"protocol"`
+	OriginURL string           `json:"originURL"`
+	Issues    []filters.Issue  `json:"issues"`
+}
+
+// lintPath returns the path without the lint prefix and whether the request
+// asked for the origin manifest to be linted
+func lintPath(p string) (string, bool) {
+	if strings.HasPrefix(p, lintPrefix+"/") {
+		return strings.TrimPrefix(p, lintPrefix), true
+	}
+
+	return p, false
+}
+
+// writeLint writes the issues found in the origin manifest as json
+func writeLint(w http.ResponseWriter, p parsers.Protocol, originURL, manifest string) {
+	resp := lintResponse{
+		Protocol:  p,
+		OriginURL: originURL,
+		Issues:    []filters.Issue{},
+	}
+
+	if issues := filters.LintManifest(p, originURL, manifest); issues != nil {
+		resp.Issues = issues
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cbsinteractive/bakery/filters"
+	test "github.com/cbsinteractive/bakery/tests"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHandler_Lint(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:BANDWIDTH=2000,CODECS="avc1.77.30,mp4a.40.2"
+link_1.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=2000
+link_1b.m3u8
+`
+
+	c := testConfig(test.MockClient(default200Response(manifest)))
+	handler := LoadHandler(c)
+	req := getRequest("/lint/b(0,1000)/origin/some/path/to/master.m3u8", t)
+	rec := getResponseRecorder()
+	handler.ServeHTTP(rec, req)
+
+	res := rec.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		t.Fatalf("expected status 200; got %v", res.StatusCode)
+	}
+
+	if ct := res.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected json content type; got %v", ct)
+	}
+
+	var got lintResponse
+	if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := lintResponse{
+		Protocol:  "hls",
+		OriginURL: "http://localhost:8080/origin/some/path/to/master.m3u8",
+		Issues: []filters.Issue{
+			{Severity: filters.SeverityWarning, Message: "variant 0 carries video without RESOLUTION"},
+			{Severity: filters.SeverityWarning, Message: "variant 1 has no CODECS"},
+			{Severity: filters.SeverityInfo, Message: "variants are not in redundant pairs, DeWeave drops the unpaired variants"},
+		},
+	}
+
+	if !cmp.Equal(got, expect) {
+		t.Errorf("Wrong lint response\ngot %v\nexpected: %v\ndiff: %v", got, expect, cmp.Diff(got, expect))
+	}
+}
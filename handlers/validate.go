@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cbsinteractive/bakery/config"
+	"github.com/cbsinteractive/bakery/filters"
+	"github.com/cbsinteractive/bakery/parsers"
+)
+
+// warningHeader carries the issues of a filtered manifest served anyway
+const warningHeader = "X-Bakery-Warning"
+
+// validateManifest checks the filtered manifest and applies the validation
+// policy when it is invalid. It returns the manifest to serve, or false if an
+// error response was written instead
+func validateManifest(w http.ResponseWriter, r *http.Request, c config.Config, mf *parsers.MediaFilters, f filters.Filter, originURL, original, filtered string) (string, bool) {
+	issues := filters.ValidateManifest(mf, filtered)
+	if !filters.HasErrors(issues) {
+		return filtered, true
+	}
+
+	msg := validationMessage(issues, f)
+	switch c.ValidationPolicy {
+	case config.ValidationPolicyOriginal:
+		return originManifest(r, c, mf.Protocol, originURL, original), true
+	case config.ValidationPolicyReject:
+		// errors are hidden from players when asked to, serving the origin manifest
+		if mf.PreventHTTPStatusError {
+			return originManifest(r, c, mf.Protocol, originURL, original), true
+		}
+
+		e := NewErrorResponse("filtered manifest is invalid", fmt.Errorf("Validation: %v", msg))
+		e.HandleError(r.Context(), w, http.StatusUnprocessableEntity)
+		return "", false
+	}
+
+	w.Header().Set(warningHeader, msg)
+	return filtered, true
+}
+
+// originManifest runs the origin manifest through the filter without any
+// filters so its relative URIs are made absolute against the origin
+func originManifest(r *http.Request, c config.Config, p parsers.Protocol, originURL, original string) string {
+	f, _ := newFilter(p, originURL, original, c)
+	manifest, err := f.FilterContent(r.Context(), &parsers.MediaFilters{Protocol: p})
+	if err != nil {
+		return original
+	}
+
+	return manifest
+}
+
+// validationMessage describes the errors found along with the filters which
+// removed renditions, the likely cause of an empty manifest
+func validationMessage(issues []filters.Issue, f filters.Filter) string {
+	var msgs []string
+	for _, issue := range issues {
+		if issue.Severity == filters.SeverityError {
+			msgs = append(msgs, issue.Message)
+		}
+	}
+	msg := strings.Join(msgs, "; ")
+
+	e, ok := f.(filters.Explainer)
+	if !ok {
+		return msg
+	}
+
+	var names []string
+	for _, name := range filters.RemovedBy(e.Decisions()) {
+		names = append(names, string(name))
+	}
+
+	if len(names) == 0 {
+		return msg
+	}
+
+	return fmt.Sprintf("%v, renditions removed by %v", msg, strings.Join(names, ", "))
+}
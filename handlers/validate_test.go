@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/cbsinteractive/bakery/config"
+	test "github.com/cbsinteractive/bakery/tests"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHandler_Filter_ValidationPolicy(t *testing.T) {
+	manifest := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=8000,CODECS="avc1.77.30,mp4a"
+link_1.m3u8
+`
+	absolute := `#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-STREAM-INF:PROGRAM-ID=0,BANDWIDTH=8000,CODECS="avc1.77.30,mp4a"
+http://existing.base/uri/link_1.m3u8
+`
+
+	tests := []struct {
+		name           string
+		policy         string
+		filters        string
+		expectStatus   int
+		expectWarning  string
+		expectManifest string
+	}{
+		{
+			name:           "when the policy is original and every variant is removed, expect the original manifest with absolute uris",
+			policy:         config.ValidationPolicyOriginal,
+			filters:        "/b(0,5000)/",
+			expectStatus:   200,
+			expectManifest: absolute,
+		},
+		{
+			name:         "when the policy is reject and every variant is removed, expect 422",
+			policy:       config.ValidationPolicyReject,
+			filters:      "/b(0,5000)/",
+			expectStatus: 422,
+		},
+		{
+			name:           "when the policy is reject and errors are prevented, expect the original manifest with absolute uris",
+			policy:         config.ValidationPolicyReject,
+			filters:        "/b(0,5000)/phe(true)/",
+			expectStatus:   200,
+			expectManifest: absolute,
+		},
+		{
+			name:           "when the policy is warn and every variant is removed, expect a warning header",
+			policy:         config.ValidationPolicyWarn,
+			filters:        "/b(0,5000)/",
+			expectStatus:   200,
+			expectWarning:  "master playlist has no variants, renditions removed by bitrate",
+			expectManifest: "#EXTM3U\n#EXT-X-VERSION:3\n",
+		},
+		{
+			name:           "when the filtered manifest is valid, expect no warning header",
+			policy:         config.ValidationPolicyReject,
+			filters:        "/b(0,10000)/",
+			expectStatus:   200,
+			expectManifest: absolute,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := testConfig(test.MockClient(default200Response("")))
+			c.ValidationPolicy = tc.policy
+			handler := LoadFilterHandler(c)
+
+			url := "/filter?baseURL=http://existing.base/uri/master.m3u8&filters=" + tc.filters
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(manifest))
+			if err != nil {
+				t.Fatalf("could not create request got error: %v", err)
+			}
+
+			rec := getResponseRecorder()
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			if res.StatusCode != tc.expectStatus {
+				t.Fatalf("expected status %v; got %v", tc.expectStatus, res.StatusCode)
+			}
+
+			if tc.expectStatus != 200 {
+				return
+			}
+
+			if warning := res.Header.Get(warningHeader); warning != tc.expectWarning {
+				t.Errorf("expected warning %q; got %q", tc.expectWarning, warning)
+			}
+
+			body, err := ioutil.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := string(body); !cmp.Equal(got, tc.expectManifest) {
+				t.Errorf("Wrong manifest returned\ngot %v\nexpected: %v\ndiff: %v",
+					got, tc.expectManifest, cmp.Diff(got, tc.expectManifest))
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package parsers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Codec families supporting constraints
+const (
+	CodecFamilyAVC  = "avc"
+	CodecFamilyHEVC = "hevc"
+)
+
+// Codec tiers, HEVC only
+const (
+	CodecTierMain = "main"
+	CodecTierHigh = "high"
+)
+
+// avcProfiles maps the AVC profile_idc to its name
+var avcProfiles = map[int]string{
+	66:  "baseline",
+	77:  "main",
+	88:  "extended",
+	100: "high",
+	110: "high10",
+	122: "high422",
+	244: "high444",
+}
+
+// hevcProfiles maps the HEVC general_profile_idc to its name
+var hevcProfiles = map[int]string{
+	1: "main",
+	2: "main10",
+	3: "mainstillpicture",
+	4: "rext",
+}
+
+// codecFamilies maps the codec filter keys supporting constraints to their family
+var codecFamilies = map[string]string{
+	"avc":  CodecFamilyAVC,
+	"hevc": CodecFamilyHEVC,
+	"hvc":  CodecFamilyHEVC,
+}
+
+// Codec is an RFC 6381 codec string parsed into its family, profile, level and
+// tier. Attributes the codec string does not carry are left empty
+type Codec struct {
+	Family  string
+	Profile string
+	Level   float64
+	Tier    string
+}
+
+// ParseCodec parses an RFC 6381 AVC or HEVC codec string, such as avc1.640028
+// or hvc1.2.4.L153.B0. It returns false for any other codec
+func ParseCodec(s string) (Codec, bool) {
+	parts := strings.Split(strings.TrimSpace(s), ".")
+	switch strings.ToLower(parts[0]) {
+	case "avc1", "avc3":
+		return parseAVC(parts[1:]), true
+	case "hvc1", "hev1":
+		return parseHEVC(parts[1:]), true
+	}
+
+	return Codec{}, false
+}
+
+// parseAVC parses the avc1.PPCCLL hexadecimal form as well as the legacy
+// avc1.PP.LL decimal one
+func parseAVC(parts []string) Codec {
+	c := Codec{Family: CodecFamilyAVC}
+	switch {
+	case len(parts) == 1 && len(parts[0]) == 6:
+		profile, pErr := strconv.ParseUint(parts[0][:2], 16, 8)
+		level, lErr := strconv.ParseUint(parts[0][4:], 16, 8)
+		if pErr != nil || lErr != nil {
+			return c
+		}
+		c.Profile = avcProfiles[int(profile)]
+		c.Level = float64(level) / 10
+	case len(parts) == 2:
+		profile, pErr := strconv.Atoi(parts[0])
+		level, lErr := strconv.Atoi(parts[1])
+		if pErr != nil || lErr != nil {
+			return c
+		}
+		c.Profile = avcProfiles[profile]
+		c.Level = float64(level) / 10
+	}
+
+	return c
+}
+
+// parseHEVC parses the hvc1.[A-C]P.compatibility.[LH]level form, where the
+// level is 30 times the level number
+func parseHEVC(parts []string) Codec {
+	c := Codec{Family: CodecFamilyHEVC}
+	if len(parts) > 0 {
+		profile := strings.TrimLeft(parts[0], "ABCabc")
+		if idc, err := strconv.Atoi(profile); err == nil {
+			c.Profile = hevcProfiles[idc]
+		}
+	}
+
+	if len(parts) > 2 && len(parts[2]) > 1 {
+		switch parts[2][0] {
+		case 'L', 'l':
+			c.Tier = CodecTierMain
+		case 'H', 'h':
+			c.Tier = CodecTierHigh
+		}
+
+		if level, err := strconv.Atoi(parts[2][1:]); err == nil && c.Tier != "" {
+			c.Level = float64(level) / 30
+		}
+	}
+
+	return c
+}
+
+// CodecConstraint restricts the renditions of a codec family to the ones
+// meeting all its conditions, such as avc(profile=high,level<=4.1)
+type CodecConstraint struct {
+	Family     string
+	Conditions []CodecCondition
+}
+
+// CodecCondition compares a codec attribute, profile, level or tier, to a value
+type CodecCondition struct {
+	Attribute string
+	Operator  string
+	Value     string
+}
+
+// Allows returns false if the codec belongs to the constrained family and fails
+// any condition. Codecs not signaling a constrained attribute are allowed
+func (c CodecConstraint) Allows(codec string) bool {
+	parsed, ok := ParseCodec(codec)
+	if !ok || parsed.Family != c.Family {
+		return true
+	}
+
+	for _, cond := range c.Conditions {
+		if !cond.holds(parsed) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c CodecCondition) holds(codec Codec) bool {
+	switch c.Attribute {
+	case "profile":
+		return codec.Profile == "" || compareStrings(codec.Profile, c.Operator, c.Value)
+	case "tier":
+		return codec.Tier == "" || compareStrings(codec.Tier, c.Operator, c.Value)
+	case "level":
+		value, err := strconv.ParseFloat(c.Value, 64)
+		return err != nil || codec.Level == 0 || compareLevels(codec.Level, c.Operator, value)
+	}
+
+	return true
+}
+
+func compareStrings(a, op, b string) bool {
+	if op == "!=" {
+		return !strings.EqualFold(a, b)
+	}
+
+	return strings.EqualFold(a, b)
+}
+
+// compareLevels compares levels rounded to a tenth, as HEVC levels are derived
+// from a division by 30
+func compareLevels(a float64, op string, b float64) bool {
+	x, y := int(a*10+0.5), int(b*10+0.5)
+	switch op {
+	case "!=":
+		return x != y
+	case "<":
+		return x < y
+	case "<=":
+		return x <= y
+	case ">":
+		return x > y
+	case ">=":
+		return x >= y
+	}
+
+	return x == y
+}
+
+// parseCodecConstraint parses the conditions of a codec constraint, such as
+// profile=main or level<=4.1, for the given codec filter key
+func parseCodecConstraint(key string, values []string) (CodecConstraint, error) {
+	family, found := codecFamilies[key]
+	if !found {
+		return CodecConstraint{}, fmt.Errorf("Codec %v does not support constraints", key)
+	}
+
+	constraint := CodecConstraint{Family: family}
+	for _, v := range values {
+		cond, err := parseCodecCondition(family, v)
+		if err != nil {
+			return CodecConstraint{}, err
+		}
+		constraint.Conditions = append(constraint.Conditions, cond)
+	}
+
+	return constraint, nil
+}
+
+func parseCodecCondition(family, s string) (CodecCondition, error) {
+	var cond CodecCondition
+	for _, op := range []string{"<=", ">=", "!=", "==", "<", ">", "="} {
+		if i := strings.Index(s, op); i > 0 {
+			cond = CodecCondition{
+				Attribute: strings.ToLower(strings.TrimSpace(s[:i])),
+				Operator:  op,
+				Value:     strings.ToLower(strings.TrimSpace(s[i+len(op):])),
+			}
+			break
+		}
+	}
+
+	if cond.Operator == "=" {
+		cond.Operator = "=="
+	}
+
+	switch cond.Attribute {
+	case "":
+		return cond, fmt.Errorf("Codec condition %v is invalid", s)
+	case "level":
+		if _, err := strconv.ParseFloat(cond.Value, 64); err != nil {
+			return cond, fmt.Errorf("Codec level %v is not a number", cond.Value)
+		}
+		return cond, nil
+	case "profile":
+		if !knownProfile(family, cond.Value) {
+			return cond, fmt.Errorf("Codec profile %v is not supported for %v", cond.Value, family)
+		}
+	case "tier":
+		if family != CodecFamilyHEVC || (cond.Value != CodecTierMain && cond.Value != CodecTierHigh) {
+			return cond, fmt.Errorf("Codec tier %v is not supported for %v", cond.Value, family)
+		}
+	default:
+		return cond, fmt.Errorf("Codec attribute %v is not supported", cond.Attribute)
+	}
+
+	if cond.Operator != "==" && cond.Operator != "!=" {
+		return cond, fmt.Errorf("Codec %v only supports the = and != operators", cond.Attribute)
+	}
+
+	return cond, nil
+}
+
+func knownProfile(family, profile string) bool {
+	profiles := avcProfiles
+	if family == CodecFamilyHEVC {
+		profiles = hevcProfiles
+	}
+
+	for _, p := range profiles {
+		if p == profile {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,151 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCodec(t *testing.T) {
+	tests := []struct {
+		codec       string
+		expectCodec Codec
+		expectOK    bool
+	}{
+		{
+			codec:       "avc1.640033",
+			expectCodec: Codec{Family: CodecFamilyAVC, Profile: "high", Level: 5.1},
+			expectOK:    true,
+		},
+		{
+			codec:       "avc1.4d401e",
+			expectCodec: Codec{Family: CodecFamilyAVC, Profile: "main", Level: 3},
+			expectOK:    true,
+		},
+		{
+			codec:       "avc1.77.30",
+			expectCodec: Codec{Family: CodecFamilyAVC, Profile: "main", Level: 3},
+			expectOK:    true,
+		},
+		{
+			codec:       "hvc1.2.4.L153.B0",
+			expectCodec: Codec{Family: CodecFamilyHEVC, Profile: "main10", Level: 5.1, Tier: CodecTierMain},
+			expectOK:    true,
+		},
+		{
+			codec:       "hev1.A1.6.H120.90",
+			expectCodec: Codec{Family: CodecFamilyHEVC, Profile: "main", Level: 4, Tier: CodecTierHigh},
+			expectOK:    true,
+		},
+		{
+			codec:       "avc1",
+			expectCodec: Codec{Family: CodecFamilyAVC},
+			expectOK:    true,
+		},
+		{
+			codec: "mp4a.40.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.codec, func(t *testing.T) {
+			got, ok := ParseCodec(tt.codec)
+			if ok != tt.expectOK {
+				t.Fatalf("ParseCodec() wrong ok returned\ngot %v\nexpected: %v", ok, tt.expectOK)
+			}
+
+			if !cmp.Equal(got, tt.expectCodec) {
+				t.Errorf("ParseCodec() wrong codec returned\ngot %+v\nexpected: %+v\ndiff: %v",
+					got, tt.expectCodec, cmp.Diff(got, tt.expectCodec))
+			}
+		})
+	}
+}
+
+func TestCodecConstraint_Allows(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    string
+		values []string
+		codec  string
+		expect bool
+	}{
+		{
+			name:   "when the avc level is below the maximum, expect it allowed",
+			key:    "avc",
+			values: []string{"level<=4.1"},
+			codec:  "avc1.4d4028",
+			expect: true,
+		},
+		{
+			name:   "when the avc level is above the maximum, expect it disallowed",
+			key:    "avc",
+			values: []string{"level<=4.1"},
+			codec:  "avc1.640033",
+		},
+		{
+			name:   "when the hevc profile is main10 and main is required, expect it disallowed",
+			key:    "hevc",
+			values: []string{"profile=main"},
+			codec:  "hvc1.2.4.L153.B0",
+		},
+		{
+			name:   "when several conditions hold, expect it allowed",
+			key:    "hvc",
+			values: []string{"profile=main10", "tier=main", "level<5.2"},
+			codec:  "hvc1.2.4.L153.B0",
+			expect: true,
+		},
+		{
+			name:   "when the codec belongs to another family, expect it allowed",
+			key:    "hevc",
+			values: []string{"profile=main"},
+			codec:  "avc1.640033",
+			expect: true,
+		},
+		{
+			name:   "when the codec does not signal the level, expect it allowed",
+			key:    "avc",
+			values: []string{"level<=4.1"},
+			codec:  "avc1",
+			expect: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseCodecConstraint(tt.key, tt.values)
+			if err != nil {
+				t.Fatalf("parseCodecConstraint() didnt expect an error to be returned, got: %v", err)
+			}
+
+			if got := c.Allows(tt.codec); got != tt.expect {
+				t.Errorf("Allows() wrong result returned\ngot %v\nexpected: %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestParseCodecConstraint_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    string
+		values []string
+	}{
+		{name: "when the codec does not support constraints", key: "mp4a", values: []string{"level<=4"}},
+		{name: "when the level is not a number", key: "avc", values: []string{"level<=high"}},
+		{name: "when the profile is unknown", key: "hevc", values: []string{"profile=high"}},
+		{name: "when the profile is compared with an order", key: "avc", values: []string{"profile>main"}},
+		{name: "when the tier is used on avc", key: "avc", values: []string{"tier=main"}},
+		{name: "when the attribute is unknown", key: "avc", values: []string{"bitdepth=10"}},
+		{name: "when there is no operator", key: "avc", values: []string{"main"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseCodecConstraint(tt.key, tt.values); err == nil {
+				t.Error("parseCodecConstraint() expected an error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	maxExpressionLength = 1024
+	maxExpressionDepth  = 32
+)
+
+// numberAttributes and stringAttributes are the rendition attributes an
+// expression can reference
+var (
+	numberAttributes = map[string]struct{}{
+		"bandwidth":     {},
+		"avg_bandwidth": {},
+		"width":         {},
+		"height":        {},
+		"fps":           {},
+		"channels":      {},
+	}
+	stringAttributes = map[string]struct{}{
+		"codecs":      {},
+		"lang":        {},
+		"type":        {},
+		"video_range": {},
+	}
+)
+
+// ExprAttributes resolves the attributes of the rendition an expression is
+// evaluated against. Attributes not advertised by the rendition are reported
+// as missing and any comparison against them is false
+type ExprAttributes interface {
+	Number(name string) (float64, bool)
+	Strings(name string) []string
+}
+
+// Expression is a parsed expr() filter such as
+// expr(codecs~"hvc" && height>1080 && fps!=60). It only holds comparisons
+// of rendition attributes against literals combined with boolean operators
+type Expression struct {
+	source string
+	root   exprNode
+}
+
+// ParseExpression parses the source of an expr() filter
+func ParseExpression(source string) (Expression, error) {
+	if len(source) > maxExpressionLength {
+		return Expression{}, fmt.Errorf("expression is longer than %v characters", maxExpressionLength)
+	}
+
+	tokens, err := tokenize(source)
+	if err != nil {
+		return Expression{}, err
+	}
+
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr(0)
+	if err != nil {
+		return Expression{}, err
+	}
+
+	if t := p.peek(); t.kind != eofToken {
+		return Expression{}, fmt.Errorf("unexpected %q at position %v", t.text, t.pos)
+	}
+
+	return Expression{source: source, root: root}, nil
+}
+
+// Match returns true if the expression holds for the given attributes
+func (e Expression) Match(attrs ExprAttributes) bool {
+	if e.root == nil {
+		return false
+	}
+
+	return e.root.eval(attrs)
+}
+
+// String returns the source of the expression
+func (e Expression) String() string {
+	return e.source
+}
+
+// Equal reports whether both expressions were parsed from the same source
+func (e Expression) Equal(o Expression) bool {
+	return e.source == o.source
+}
+
+// MarshalJSON encodes the expression as its source
+func (e Expression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.source)
+}
+
+// UnmarshalJSON parses the expression from its source
+func (e *Expression) UnmarshalJSON(data []byte) error {
+	var source string
+	if err := json.Unmarshal(data, &source); err != nil {
+		return err
+	}
+
+	parsed, err := ParseExpression(source)
+	if err != nil {
+		return fmt.Errorf("Expression: %w", err)
+	}
+
+	*e = parsed
+	return nil
+}
+
+type exprNode interface {
+	eval(attrs ExprAttributes) bool
+}
+
+type andNode struct{ left, right exprNode }
+
+func (n andNode) eval(attrs ExprAttributes) bool {
+	return n.left.eval(attrs) && n.right.eval(attrs)
+}
+
+type orNode struct{ left, right exprNode }
+
+func (n orNode) eval(attrs ExprAttributes) bool {
+	return n.left.eval(attrs) || n.right.eval(attrs)
+}
+
+type notNode struct{ operand exprNode }
+
+func (n notNode) eval(attrs ExprAttributes) bool {
+	return !n.operand.eval(attrs)
+}
+
+// numberComparison compares a numeric attribute against a number
+type numberComparison struct {
+	attr  string
+	op    string
+	value float64
+}
+
+func (n numberComparison) eval(attrs ExprAttributes) bool {
+	v, found := attrs.Number(n.attr)
+	if !found {
+		return false
+	}
+
+	switch n.op {
+	case "==":
+		return v == n.value
+	case "!=":
+		return v != n.value
+	case "<":
+		return v < n.value
+	case "<=":
+		return v <= n.value
+	case ">":
+		return v > n.value
+	case ">=":
+		return v >= n.value
+	}
+
+	return false
+}
+
+// stringComparison compares a string attribute against a string, ignoring case.
+// Attributes holding several values, such as codecs, match if any value does
+type stringComparison struct {
+	attr  string
+	op    string
+	value string
+}
+
+func (n stringComparison) eval(attrs ExprAttributes) bool {
+	values := attrs.Strings(n.attr)
+	if len(values) == 0 {
+		return false
+	}
+
+	var match bool
+	for _, v := range values {
+		switch n.op {
+		case "==", "!=":
+			match = strings.EqualFold(v, n.value)
+		case "~":
+			match = strings.Contains(strings.ToLower(v), strings.ToLower(n.value))
+		}
+
+		if match {
+			break
+		}
+	}
+
+	if n.op == "!=" {
+		return !match
+	}
+
+	return match
+}
+
+type tokenKind int
+
+const (
+	eofToken tokenKind = iota
+	identToken
+	numberToken
+	stringToken
+	operatorToken
+	lparenToken
+	rparenToken
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// tokenize splits an expression into tokens. Keywords and, or and not are
+// returned as their operator equivalent
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{lparenToken, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{rparenToken, ")", i})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(source[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at position %v", i)
+			}
+			tokens = append(tokens, token{stringToken, source[i+1 : i+1+end], i})
+			i += end + 2
+		case isDigit(c) || c == '.':
+			start := i
+			for i < len(source) && (isDigit(source[i]) || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{numberToken, source[start:i], start})
+		case c == '_' || unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(source) && (source[i] == '_' || isDigit(source[i]) || unicode.IsLetter(rune(source[i]))) {
+				i++
+			}
+
+			word := source[start:i]
+			switch strings.ToLower(word) {
+			case "and":
+				tokens = append(tokens, token{operatorToken, "&&", start})
+			case "or":
+				tokens = append(tokens, token{operatorToken, "||", start})
+			case "not":
+				tokens = append(tokens, token{operatorToken, "!", start})
+			default:
+				tokens = append(tokens, token{identToken, word, start})
+			}
+		default:
+			op := operatorAt(source[i:])
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at position %v", c, i)
+			}
+
+			text := op
+			if op == "=" {
+				text = "=="
+			}
+			tokens = append(tokens, token{operatorToken, text, i})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{eofToken, "", len(source)}), nil
+}
+
+// operatorAt returns the operator at the start of s, longest first
+func operatorAt(s string) string {
+	for _, op := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "=", "!", "~"} {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+
+	return ""
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// exprParser is a recursive descent parser for the grammar
+//
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" or ")" | comparison
+//	comparison = attribute operator literal
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != eofToken {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *exprParser) parseOr(depth int) (exprNode, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for t := p.peek(); t.kind == operatorToken && t.text == "||"; t = p.peek() {
+		p.next()
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseAnd(depth int) (exprNode, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	for t := p.peek(); t.kind == operatorToken && t.text == "&&"; t = p.peek() {
+		p.next()
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseUnary(depth int) (exprNode, error) {
+	if depth > maxExpressionDepth {
+		return nil, fmt.Errorf("expression is nested deeper than %v levels", maxExpressionDepth)
+	}
+
+	switch t := p.peek(); {
+	case t.kind == operatorToken && t.text == "!":
+		p.next()
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	case t.kind == lparenToken:
+		p.next()
+		node, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != rparenToken {
+			return nil, fmt.Errorf("expected \")\" at position %v", t.pos)
+		}
+		return node, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	attr := p.next()
+	if attr.kind != identToken {
+		return nil, fmt.Errorf("expected an attribute at position %v", attr.pos)
+	}
+
+	op := p.next()
+	if op.kind != operatorToken || op.text == "&&" || op.text == "||" || op.text == "!" {
+		return nil, fmt.Errorf("expected a comparison operator after %v at position %v", attr.text, op.pos)
+	}
+
+	value := p.next()
+	name := strings.ToLower(attr.text)
+
+	if _, found := numberAttributes[name]; found {
+		if value.kind != numberToken {
+			return nil, fmt.Errorf("%v expects a number at position %v", name, value.pos)
+		}
+		if op.text == "~" {
+			return nil, fmt.Errorf("%v does not support the ~ operator", name)
+		}
+
+		n, err := strconv.ParseFloat(value.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %v", value.text, value.pos)
+		}
+
+		return numberComparison{attr: name, op: op.text, value: n}, nil
+	}
+
+	if _, found := stringAttributes[name]; found {
+		if value.kind != stringToken {
+			return nil, fmt.Errorf("%v expects a quoted string at position %v", name, value.pos)
+		}
+		if op.text != "==" && op.text != "!=" && op.text != "~" {
+			return nil, fmt.Errorf("%v only supports the ==, != and ~ operators", name)
+		}
+
+		return stringComparison{attr: name, op: op.text, value: value.text}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported attribute %q", attr.text)
+}
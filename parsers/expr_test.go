@@ -0,0 +1,158 @@
+package parsers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// attributes is a fixed set of rendition attributes
+type attributes struct {
+	numbers map[string]float64
+	strings map[string][]string
+}
+
+func (a attributes) Number(name string) (float64, bool) {
+	v, found := a.numbers[name]
+	return v, found
+}
+
+func (a attributes) Strings(name string) []string {
+	return a.strings[name]
+}
+
+func mustParseExpression(source string) Expression {
+	e, err := ParseExpression(source)
+	if err != nil {
+		panic(err)
+	}
+
+	return e
+}
+
+func TestExpression_Match(t *testing.T) {
+	hevc1080p60 := attributes{
+		numbers: map[string]float64{"bandwidth": 6000000, "width": 1920, "height": 1080, "fps": 60},
+		strings: map[string][]string{"codecs": {"hvc1.2.4.L123.B0", "ec-3"}, "type": {"video", "audio"}},
+	}
+
+	audio := attributes{
+		numbers: map[string]float64{"channels": 6},
+		strings: map[string][]string{"type": {"audio"}, "lang": {"en"}},
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		attrs  attributes
+		expect bool
+	}{
+		{
+			name:   "when all comparisons of a conjunction hold, expect a match",
+			source: `codecs~"hvc" && height>=1080 && fps==60`,
+			attrs:  hevc1080p60,
+			expect: true,
+		},
+		{
+			name:   "when a negated comparison holds, expect no match",
+			source: `codecs~"hvc" && height>=1080 && !(fps==60)`,
+			attrs:  hevc1080p60,
+		},
+		{
+			name:   "when keywords are used as operators, expect them evaluated as symbols",
+			source: `type=='text' or not bandwidth < 5000000`,
+			attrs:  hevc1080p60,
+			expect: true,
+		},
+		{
+			name:   "when an attribute is missing, expect the comparison to be false",
+			source: `height<720`,
+			attrs:  audio,
+		},
+		{
+			name:   "when not equal is applied to a missing attribute, expect the comparison to be false",
+			source: `lang!="es"`,
+			attrs:  hevc1080p60,
+		},
+		{
+			name:   "when strings are compared, expect case to be ignored",
+			source: `lang=="EN" && channels>2`,
+			attrs:  audio,
+			expect: true,
+		},
+		{
+			name:   "when a codec is compared for equality, expect the whole codec to be compared",
+			source: `codecs=="ec-3"`,
+			attrs:  hevc1080p60,
+			expect: true,
+		},
+		{
+			name:   "when and is mixed with or, expect and to bind tighter",
+			source: `fps==30 && height==1080 || channels==6`,
+			attrs:  audio,
+			expect: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := ParseExpression(tt.source)
+			if err != nil {
+				t.Fatalf("ParseExpression() didnt expect an error to be returned, got: %v", err)
+			}
+
+			if got := e.Match(tt.attrs); got != tt.expect {
+				t.Errorf("Match() = %v, expected %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestExpression_ParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{name: "unknown attribute", source: `bitrate > 10`},
+		{name: "number attribute compared to a string", source: `height == "1080"`},
+		{name: "string attribute compared to a number", source: `lang == 1`},
+		{name: "string attribute ordered", source: `lang < "en"`},
+		{name: "contains applied to a number attribute", source: `height ~ 10`},
+		{name: "missing operand", source: `height >`},
+		{name: "unbalanced parentheses", source: `(height > 10`},
+		{name: "trailing tokens", source: `height > 10 width`},
+		{name: "unterminated string", source: `lang == "en`},
+		{name: "unsupported character", source: `height > 10; lang == "en"`},
+		{name: "empty expression", source: ``},
+		{name: "nested too deep", source: `!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!(height > 10)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseExpression(tt.source); err == nil {
+				t.Errorf("ParseExpression(%q) expected an error, got nil", tt.source)
+			}
+		})
+	}
+}
+
+func TestExpression_JSON(t *testing.T) {
+	mf := MediaFilters{Expressions: []Expression{mustParseExpression(`height > 1080`)}}
+
+	data, err := json.Marshal(mf)
+	if err != nil {
+		t.Fatalf("Marshal() didnt expect an error to be returned, got: %v", err)
+	}
+
+	got, err := JSONParse(data, ProtocolHLS)
+	if err != nil {
+		t.Fatalf("JSONParse() didnt expect an error to be returned, got: %v", err)
+	}
+
+	if len(got.Expressions) != 1 || !got.Expressions[0].Equal(mf.Expressions[0]) {
+		t.Errorf("Wrong expressions decoded\ngot %v\nexpected: %v", got.Expressions, mf.Expressions)
+	}
+
+	if _, err := JSONParse([]byte(`{"Expressions":["height >"]}`), ProtocolHLS); err == nil {
+		t.Error("JSONParse() expected an error for an invalid expression, got nil")
+	}
+}
@@ -0,0 +1,103 @@
+package parsers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// frameRateTolerance is the difference under which two frame rates are equal,
+// so that 29.97, 29.970 and 30000/1001 all match
+const frameRateTolerance = 0.01
+
+// FrameRateRange keeps the renditions with a frame rate between Min and Max.
+// A Max of 0 leaves the range unbounded
+type FrameRateRange struct {
+	Min float64 `json:",omitempty"`
+	Max float64 `json:",omitempty"`
+}
+
+// Contains returns true if the frame rate is within the range
+func (r FrameRateRange) Contains(fps float64) bool {
+	if fps < r.Min-frameRateTolerance {
+		return false
+	}
+
+	return r.Max == 0 || fps <= r.Max+frameRateTolerance
+}
+
+// ParseFrameRate parses a frame rate written as a decimal, such as 29.970,
+// or as a ratio, such as 30000/1001 or 30000:1001
+func ParseFrameRate(s string) (float64, bool) {
+	if s == "" {
+		return 0, false
+	}
+
+	parts := strings.SplitN(strings.ReplaceAll(s, ":", "/"), "/", 2)
+	num, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil || num < 0 {
+		return 0, false
+	}
+
+	if len(parts) == 1 {
+		return num, true
+	}
+
+	den, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || den <= 0 {
+		return 0, false
+	}
+
+	return num / den, true
+}
+
+// EqualFrameRates returns true if both frame rates are equal within tolerance
+func EqualFrameRates(a, b float64) bool {
+	return math.Abs(a-b) < frameRateTolerance
+}
+
+// isFrameRateRange returns true if any fps() value is a range bound, written
+// as min:<rate> or max:<rate>, such as fps(min:24,max:30) or fps(min:50)
+func isFrameRateRange(values []string) bool {
+	for _, value := range values {
+		if strings.HasPrefix(value, "min:") || strings.HasPrefix(value, "max:") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parse sets the range from min:<rate> and max:<rate> values, where a rate
+// may itself be a ratio such as max:30000:1001
+func (r *FrameRateRange) parse(values []string) error {
+	for _, value := range values {
+		kv := strings.SplitN(value, ":", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("expected min:<rate> or max:<rate>, got %q", value)
+		}
+
+		rate, ok := ParseFrameRate(kv[1])
+		switch key := kv[0]; key {
+		case "min":
+			if !ok {
+				return fmt.Errorf("Frame rate %v is not a number or a ratio", kv[1])
+			}
+			r.Min = rate
+		case "max":
+			if !ok || rate == 0 {
+				return fmt.Errorf("Frame rate %v is not a positive number or ratio", kv[1])
+			}
+			r.Max = rate
+		default:
+			return fmt.Errorf("expected min:<rate> or max:<rate>, got %q", value)
+		}
+	}
+
+	if r.Max != 0 && r.Min > r.Max {
+		return fmt.Errorf("invalid range for provided values: ( %v, %v )", r.Min, r.Max)
+	}
+
+	return nil
+}
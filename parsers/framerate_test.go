@@ -0,0 +1,50 @@
+package parsers
+
+import "testing"
+
+func TestParseFrameRate(t *testing.T) {
+	tests := []struct {
+		fps      string
+		expect   float64
+		expectOK bool
+	}{
+		{fps: "29.970", expect: 29.97, expectOK: true},
+		{fps: "30000/1001", expect: 30000.0 / 1001, expectOK: true},
+		{fps: "30000:1001", expect: 30000.0 / 1001, expectOK: true},
+		{fps: "60", expect: 60, expectOK: true},
+		{fps: "30/0"},
+		{fps: "fast"},
+		{fps: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fps, func(t *testing.T) {
+			got, ok := ParseFrameRate(tt.fps)
+			if ok != tt.expectOK || got != tt.expect {
+				t.Errorf("ParseFrameRate() wrong frame rate returned\ngot %v, %v\nexpected: %v, %v", got, ok, tt.expect, tt.expectOK)
+			}
+		})
+	}
+}
+
+func TestFrameRateRange_Contains(t *testing.T) {
+	tests := []struct {
+		name   string
+		r      FrameRateRange
+		fps    float64
+		expect bool
+	}{
+		{name: "when 29.97 is checked against a range up to 29.97", r: FrameRateRange{Max: 29.97}, fps: 30000.0 / 1001, expect: true},
+		{name: "when 60 is checked against a range up to 30", r: FrameRateRange{Max: 30}, fps: 60},
+		{name: "when 60 is checked against an unbounded range from 50", r: FrameRateRange{Min: 50}, fps: 60, expect: true},
+		{name: "when 24 is checked against an unbounded range from 50", r: FrameRateRange{Min: 50}, fps: 24},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.Contains(tt.fps); got != tt.expect {
+				t.Errorf("Contains() wrong result returned\ngot %v\nexpected: %v", got, tt.expect)
+			}
+		})
+	}
+}
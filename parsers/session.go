@@ -0,0 +1,98 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// sessionDataIDRegexp matches reverse DNS DATA-IDs, such as com.example.title
+var sessionDataIDRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)+$`)
+
+// SessionData is an EXT-X-SESSION-DATA entry added to HLS master playlists,
+// carrying either a Value or the URI of a JSON document
+type SessionData struct {
+	ID       string `json:"id"`
+	Value    string `json:"value,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+// SessionDataList is the session data added to every HLS master playlist,
+// decoded once from its JSON configuration, such as
+// [{"id":"com.example.chapters","uri":"https://cdn.com/chapters.json"}]
+type SessionDataList []SessionData
+
+// Decode parses and validates the JSON session data configuration
+func (l *SessionDataList) Decode(value string) error {
+	if value == "" {
+		*l = nil
+		return nil
+	}
+
+	var data []SessionData
+	if err := json.Unmarshal([]byte(value), &data); err != nil {
+		return fmt.Errorf("parsing session data: %w", err)
+	}
+
+	for _, d := range data {
+		if err := d.Validate(); err != nil {
+			return fmt.Errorf("parsing session data: %w", err)
+		}
+	}
+
+	*l = data
+	return nil
+}
+
+// Validate returns an error if the session data has no reverse DNS ID, does not
+// carry exactly one of Value and URI, or carries quotes or control characters
+// which would break out of the quoted attributes of the tag
+func (d SessionData) Validate() error {
+	if d.ID == "" {
+		return fmt.Errorf("Session data requires an id")
+	}
+
+	if !sessionDataIDRegexp.MatchString(d.ID) {
+		return fmt.Errorf("Session data id %q is not a reverse DNS name", d.ID)
+	}
+
+	if (d.Value == "") == (d.URI == "") {
+		return fmt.Errorf("Session data %v requires either a value or a uri", d.ID)
+	}
+
+	if d.Language != "" && !languageTagRegexp.MatchString(d.Language) {
+		return fmt.Errorf("Session data %v language %q is not a language tag", d.ID, d.Language)
+	}
+
+	for _, v := range []string{d.Value, d.URI} {
+		if !quotableString(v) {
+			return fmt.Errorf("Session data %v carries a quote or a control character", d.ID)
+		}
+	}
+
+	return nil
+}
+
+// quotableString returns true if the string can be written as a quoted-string
+// attribute, which may not carry double quotes, line feeds or carriage returns
+func quotableString(s string) bool {
+	return !strings.ContainsRune(s, '"') && strings.IndexFunc(s, unicode.IsControl) < 0
+}
+
+// parseSessionData parses the sd() values, a DATA-ID and its value followed
+// by an optional language, such as sd(com.example.content-id,1234,en)
+func parseSessionData(values []string) (SessionData, error) {
+	if len(values) < 2 || len(values) > 3 {
+		return SessionData{}, fmt.Errorf("expected an id, a value and an optional language, got %v values", len(values))
+	}
+
+	d := SessionData{ID: values[0], Value: values[1]}
+	if len(values) == 3 {
+		d.Language = values[2]
+	}
+
+	return d, d.Validate()
+}
@@ -0,0 +1,119 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSessionData_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      SessionData
+		expectErr bool
+	}{
+		{
+			name: "when session data carries a value, expect no error",
+			data: SessionData{ID: "com.example.title", Value: "Star Trek: Discovery", Language: "en-US"},
+		},
+		{
+			name: "when session data carries a uri, expect no error",
+			data: SessionData{ID: "com.example.chapters", URI: "https://cdn.com/chapters.json?id=1"},
+		},
+		{
+			name:      "when the id is not a reverse dns name, expect an error",
+			data:      SessionData{ID: "title", Value: "1234"},
+			expectErr: true,
+		},
+		{
+			name:      "when the id closes the attribute, expect an error",
+			data:      SessionData{ID: `com.example.id",VALUE="x`, Value: "1234"},
+			expectErr: true,
+		},
+		{
+			name:      "when the value closes the attribute to add another, expect an error",
+			data:      SessionData{ID: "com.example.id", Value: `1234",URI="https://evil.com/x.json`},
+			expectErr: true,
+		},
+		{
+			name:      "when the value starts a new line to add a tag, expect an error",
+			data:      SessionData{ID: "com.example.id", Value: "1234\n#EXT-X-SESSION-KEY:METHOD=AES-128,URI=\"https://evil.com/key\""},
+			expectErr: true,
+		},
+		{
+			name:      "when the uri carries a carriage return, expect an error",
+			data:      SessionData{ID: "com.example.id", URI: "https://cdn.com/a.json\r\nhttps://evil.com/variant.m3u8"},
+			expectErr: true,
+		},
+		{
+			name:      "when the value carries another control character, expect an error",
+			data:      SessionData{ID: "com.example.id", Value: "12\x0034"},
+			expectErr: true,
+		},
+		{
+			name:      "when the language is not a language tag, expect an error",
+			data:      SessionData{ID: "com.example.id", Value: "1234", Language: "en\"\n#EXT-X-STREAM-INF"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.data.Validate(); (err != nil) != tt.expectErr {
+				t.Errorf("Validate() expected error %v, got: %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestSessionDataList_Decode(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		expect    SessionDataList
+		expectErr bool
+	}{
+		{
+			name: "when no session data is configured, expect none",
+		},
+		{
+			name:   "when session data is configured, expect it to be parsed",
+			config: `[{"id":"com.example.chapters","uri":"https://cdn.com/chapters.json","language":"en"}]`,
+			expect: SessionDataList{{ID: "com.example.chapters", URI: "https://cdn.com/chapters.json", Language: "en"}},
+		},
+		{
+			name:      "when session data has neither a value nor a uri, expect an error",
+			config:    `[{"id":"com.example.chapters"}]`,
+			expectErr: true,
+		},
+		{
+			name:      "when session data has both a value and a uri, expect an error",
+			config:    `[{"id":"com.example.chapters","value":"1","uri":"https://cdn.com/chapters.json"}]`,
+			expectErr: true,
+		},
+		{
+			name:      "when session data injects a tag through its value, expect an error",
+			config:    `[{"id":"com.example.id","value":"1\"\n#EXT-X-SESSION-KEY:METHOD=AES-128,URI=\"https://evil.com/key\""}]`,
+			expectErr: true,
+		},
+		{
+			name:      "when session data is not json, expect an error",
+			config:    `com.example.chapters`,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got SessionDataList
+			err := got.Decode(tt.config)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("Decode() expected error %v, got: %v", tt.expectErr, err)
+			}
+
+			if !cmp.Equal(got, tt.expect) {
+				t.Errorf("Wrong session data returned\ngot %v\nexpected: %v\ndiff: %v", got, tt.expect, cmp.Diff(got, tt.expect))
+			}
+		})
+	}
+}
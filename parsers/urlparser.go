@@ -1,6 +1,7 @@
 package parsers
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"path"
@@ -12,28 +13,49 @@ import (
 
 // MediaFilters is a struct that carry all the information passed via url
 type MediaFilters struct {
-	Videos                 NestedFilters `json:",omitempty"`
-	Audios                 NestedFilters `json:",omitempty"`
-	Captions               NestedFilters `json:",omitempty"`
-	ContentTypes           []string      `json:",omitempty"`
-	Plugins                []string      `json:",omitempty"`
-	Tags                   *Tags         `json:",omitempty"`
-	Trim                   *Trim         `json:",omitempty"`
-	Bitrate                *Bitrate      `json:",omitempty"`
-	FrameRate              []string      `json:",omitempty"`
-	DeWeave                bool          `json:",omitempty"`
-	PreventHTTPStatusError bool          `json:",omitempty"`
-	Protocol               Protocol      `json:"protocol"`
+	Videos                 NestedFilters   `json:",omitempty"`
+	Audios                 NestedFilters   `json:",omitempty"`
+	Captions               NestedFilters   `json:",omitempty"`
+	ContentTypes           []string        `json:",omitempty"`
+	Plugins                []string        `json:",omitempty"`
+	Tags                   *Tags           `json:",omitempty"`
+	Trim                   *Trim           `json:",omitempty"`
+	Bitrate                *Bitrate        `json:",omitempty"`
+	FrameRate              []string        `json:",omitempty"`
+	FrameRateRange         *FrameRateRange `json:",omitempty"`
+	VideoRanges            []string        `json:",omitempty"`
+	DRMSystems             []string        `json:",omitempty"`
+	Expressions            []Expression    `json:",omitempty"`
+	Ladder                 *Ladder         `json:",omitempty"`
+	Sort                   *Sort           `json:",omitempty"`
+	First                  *First          `json:",omitempty"`
+	Defaults               *Defaults       `json:",omitempty"`
+	DeWeave                bool            `json:",omitempty"`
+	SessionKeys            bool            `json:",omitempty"`
+	SessionData            []SessionData   `json:",omitempty"`
+	PreventHTTPStatusError bool            `json:",omitempty"`
+	Protocol               Protocol        `json:"protocol"`
 }
 
 // NestedFilters is a struct that holds values of filters
 // that can be nested within certain Media Filters
 type NestedFilters struct {
-	Bitrate  *Bitrate `json:",omitempty"`
-	Codecs   []string `json:",omitempty"`
-	Language []string `json:",omitempty"`
+	Bitrate          *Bitrate          `json:",omitempty"`
+	Codecs           []string          `json:",omitempty"`
+	Language         []string          `json:",omitempty"`
+	MaxChannels      int               `json:",omitempty"`
+	CodecConstraints []CodecConstraint `json:",omitempty"`
+	Forced           string            `json:",omitempty"`
+	InstreamIDs      []string          `json:",omitempty"`
+	Roles            []string          `json:",omitempty"`
 }
 
+// Forced subtitles filter values, dropping forced subtitles or keeping only them
+const (
+	ForcedDrop = "drop"
+	ForcedOnly = "only"
+)
+
 // Protocol describe the valid protocols
 type Protocol string
 
@@ -58,6 +80,46 @@ type Bitrate struct {
 	Min int `json:",omitempty"`
 }
 
+// Ladder limits the video renditions of each ladder to Max renditions, chosen
+// according to Keep, and enforces a minimum bandwidth ratio between adjacent ones
+type Ladder struct {
+	Max      int     `json:",omitempty"`
+	Keep     string  `json:",omitempty"`
+	MinRatio float64 `json:",omitempty"`
+}
+
+// Renditions kept by the ladder filter when limiting its size
+const (
+	LadderKeepHighest = "highest"
+	LadderKeepLowest  = "lowest"
+	LadderKeepEven    = "even"
+)
+
+// Sort orders the renditions by bandwidth, the only field supported
+type Sort struct {
+	Field string `json:",omitempty"`
+	Order string `json:",omitempty"`
+}
+
+// Sort orders
+const (
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+// First moves the rendition with the highest bandwidth not above Bandwidth
+// first, or the lowest one if they are all above it
+type First struct {
+	Bandwidth int `json:",omitempty"`
+}
+
+// Defaults holds the languages, in order of preference, of the audio and
+// caption renditions to select as default
+type Defaults struct {
+	Audio    []string `json:",omitempty"`
+	Captions []string `json:",omitempty"`
+}
+
 // Tags holds values of HLS tags that are to be suppressed
 // from the manifest
 type Tags struct {
@@ -65,6 +127,26 @@ type Tags struct {
 	IFrame bool `json:",omitempty"`
 }
 
+var roleSupported = map[string]struct{}{
+	"main":          struct{}{},
+	"alternate":     struct{}{},
+	"supplementary": struct{}{},
+	"commentary":    struct{}{},
+	"dub":           struct{}{},
+	"description":   struct{}{}, //audio description, describes-video in HLS
+	"caption":       struct{}{}, //transcribes-spoken-dialog in HLS
+	"subtitle":      struct{}{},
+	"sign":          struct{}{},
+	"emergency":     struct{}{},
+}
+
+var drmSupported = map[string]struct{}{
+	"widevine":  struct{}{},
+	"playready": struct{}{},
+	"fairplay":  struct{}{},
+	"clearkey":  struct{}{}, //AES-128 identity keys in HLS
+}
+
 var urlParseRegexp = regexp.MustCompile(`(.*?)\((.*)\)`)
 var nestedFilterRegexp = regexp.MustCompile(`\),`)
 
@@ -89,29 +171,108 @@ var contentSupported = map[string]struct{}{
 	"video": struct{}{},
 }
 
+var videoRangeSupported = map[string]struct{}{
+	"sdr": struct{}{},
+	"pq":  struct{}{}, //HDR10 and Dolby Vision
+	"hlg": struct{}{},
+}
+
 func keyError(key string, e error) (string, *MediaFilters, error) {
 	return "", &MediaFilters{}, fmt.Errorf("%v: %w", key, e)
 }
 
+func pathError(key string, e error) (string, error) {
+	return "", fmt.Errorf("%v: %w", key, e)
+}
+
 // URLParse will generate a MediaFilters struct with
 // all the filters that needs to be applied to the
 // master manifest. It will also return the master manifest
 // url without the filters.
 func URLParse(urlpath string) (string, *MediaFilters, error) {
 	mf := new(MediaFilters)
-	parts := strings.Split(urlpath, "/")
-	re := urlParseRegexp
-	masterManifestPath := "/"
 
-	if strings.Contains(urlpath, ".m3u8") {
-		mf.Protocol = ProtocolHLS
-	} else if strings.Contains(urlpath, ".mpd") {
-		mf.Protocol = ProtocolDASH
-	} else if strings.Contains(urlpath, ".vtt") {
-		mf.Protocol = ProtocolVTT
-	} else {
+	protocol, found := ProtocolFromPath(urlpath)
+	if !found {
 		return keyError("Protocol", fmt.Errorf("unsupported protocol"))
 	}
+	mf.Protocol = protocol
+
+	masterManifestPath, err := mf.parsePath(urlpath)
+	if err != nil {
+		return "", &MediaFilters{}, err
+	}
+
+	return masterManifestPath, mf, nil
+}
+
+// FilterParse will generate a MediaFilters struct for the given protocol
+// from a path only carrying filters, such as /v(hevc)/b(0,3000000)/
+func FilterParse(filterPath string, protocol Protocol) (*MediaFilters, error) {
+	mf := &MediaFilters{Protocol: protocol}
+
+	if _, err := mf.parsePath(filterPath); err != nil {
+		return &MediaFilters{}, err
+	}
+
+	return mf, nil
+}
+
+// JSONParse will generate a MediaFilters struct from its json representation,
+// validating and normalizing it the same way filters parsed from a path are
+func JSONParse(data []byte, protocol Protocol) (*MediaFilters, error) {
+	mf := new(MediaFilters)
+	if err := json.Unmarshal(data, mf); err != nil {
+		return &MediaFilters{}, fmt.Errorf("Filters: %w", err)
+	}
+
+	if err := mf.validate(); err != nil {
+		return &MediaFilters{}, err
+	}
+
+	mf.Protocol = protocol
+	mf.normalizeBitrateFilter()
+
+	return mf, nil
+}
+
+// ProtocolFromPath returns the protocol of the manifest referenced by
+// the path and whether a supported protocol was found
+func ProtocolFromPath(p string) (Protocol, bool) {
+	switch {
+	case strings.Contains(p, ".m3u8"):
+		return ProtocolHLS, true
+	case strings.Contains(p, ".mpd"):
+		return ProtocolDASH, true
+	case strings.Contains(p, ".vtt"):
+		return ProtocolVTT, true
+	}
+
+	return "", false
+}
+
+// ProtocolFromContent returns the protocol of a manifest by inspecting
+// its content and whether a supported protocol was found
+func ProtocolFromContent(content string) (Protocol, bool) {
+	content = strings.TrimSpace(content)
+	switch {
+	case strings.HasPrefix(content, "WEBVTT"):
+		return ProtocolVTT, true
+	case strings.HasPrefix(content, "#EXTM3U"):
+		return ProtocolHLS, true
+	case strings.Contains(content, "<MPD"):
+		return ProtocolDASH, true
+	}
+
+	return "", false
+}
+
+// parsePath sets the filters found in each part of the path and returns
+// the remaining parts joined as the master manifest path
+func (mf *MediaFilters) parsePath(urlpath string) (string, error) {
+	parts := strings.Split(urlpath, "/")
+	re := urlParseRegexp
+	masterManifestPath := "/"
 
 	for _, part := range parts {
 		// FindStringSubmatch should return a slice with
@@ -135,29 +296,44 @@ func URLParse(urlpath string) (string, *MediaFilters, error) {
 		case "v":
 			for _, nf := range nestedFilters {
 				if err := mf.Videos.parse(nf); err != nil {
-					return keyError("Video", err)
+					return pathError("Video", err)
 				}
 			}
 		case "a":
 			for _, nf := range nestedFilters {
 				if err := mf.Audios.parse(nf); err != nil {
-					return keyError("Audio", err)
+					return pathError("Audio", err)
 				}
 			}
 		case "c":
 			for _, nf := range nestedFilters {
 				if err := mf.Captions.parse(nf); err != nil {
-					return keyError("Captions", err)
+					return pathError("Captions", err)
 				}
 			}
 		case "ct":
 			for _, contentType := range filters {
 				if _, valid := contentSupported[contentType]; !valid {
 					err := fmt.Errorf("Content Type %v is not supported", contentType)
-					return keyError("Content Type", err)
+					return pathError("Content Type", err)
 				}
 				mf.ContentTypes = append(mf.ContentTypes, contentType)
 			}
+		case "ch":
+			max, err := parseMaxChannels(filters)
+			if err != nil {
+				return pathError("Channels", err)
+			}
+
+			mf.Audios.MaxChannels = max
+		case "role":
+			roles, err := parseRoles(filters)
+			if err != nil {
+				return pathError("Role", err)
+			}
+
+			mf.Audios.Roles = append(mf.Audios.Roles, roles...)
+			mf.Captions.Roles = append(mf.Captions.Roles, roles...)
 		case "l":
 			for _, lang := range filters {
 				mf.Audios.Language = append(mf.Audios.Language, lang)
@@ -166,7 +342,7 @@ func URLParse(urlpath string) (string, *MediaFilters, error) {
 		case "b":
 			x, y, err := parseAndValidateInts(filters, math.MaxInt32)
 			if err != nil {
-				return keyError("Bitrate", err)
+				return pathError("Bitrate", err)
 			}
 
 			mf.Bitrate = &Bitrate{
@@ -176,7 +352,7 @@ func URLParse(urlpath string) (string, *MediaFilters, error) {
 		case "t":
 			x, y, err := parseAndValidateInts(filters, int(time.Now().Unix()))
 			if err != nil {
-				return keyError("Trim", err)
+				return pathError("Trim", err)
 			}
 
 			mf.Trim = &Trim{
@@ -187,29 +363,103 @@ func URLParse(urlpath string) (string, *MediaFilters, error) {
 			mf.Tags = &Tags{}
 			mf.Tags.parse(filters)
 		case "fps": //fps types in hls=float64, dash=string
+			if isFrameRateRange(filters) {
+				mf.FrameRateRange = &FrameRateRange{}
+				if err := mf.FrameRateRange.parse(filters); err != nil {
+					return pathError("Frame Rate", err)
+				}
+				continue
+			}
+
 			for _, framerate := range filters {
+				if _, valid := ParseFrameRate(framerate); !valid {
+					err := fmt.Errorf("Frame rate %v is not a number or a ratio", framerate)
+					return pathError("Frame Rate", err)
+				}
 				fr := strings.ReplaceAll(framerate, ":", "/")
 				mf.FrameRate = append(mf.FrameRate, fr)
 			}
+		case "vr":
+			for _, vr := range filters {
+				if _, valid := videoRangeSupported[vr]; !valid {
+					err := fmt.Errorf("Video range %v is not supported", vr)
+					return pathError("Video Range", err)
+				}
+				mf.VideoRanges = append(mf.VideoRanges, vr)
+			}
+		case "drm":
+			for _, drm := range filters {
+				system := strings.ToLower(drm)
+				if _, valid := drmSupported[system]; !valid {
+					err := fmt.Errorf("DRM system %v is not supported", drm)
+					return pathError("DRM", err)
+				}
+				mf.DRMSystems = append(mf.DRMSystems, system)
+			}
+		case "expr":
+			e, err := ParseExpression(subparts[2])
+			if err != nil {
+				return pathError("Expression", err)
+			}
+
+			mf.Expressions = append(mf.Expressions, e)
+		case "ladder":
+			mf.Ladder = &Ladder{}
+			if err := mf.Ladder.parse(filters); err != nil {
+				return pathError("Ladder", err)
+			}
+		case "sort":
+			mf.Sort = &Sort{}
+			if err := mf.Sort.parse(filters); err != nil {
+				return pathError("Sort", err)
+			}
+		case "def":
+			mf.Defaults = &Defaults{}
+			if err := mf.Defaults.parse(nestedFilters); err != nil {
+				return pathError("Defaults", err)
+			}
+		case "first":
+			mf.First = &First{}
+			if err := mf.First.parse(filters); err != nil {
+				return pathError("First", err)
+			}
 		case "dw":
 			if len(filters) > 1 {
-				return keyError("DeWeave", fmt.Errorf("Only accepts one boolean value"))
+				return pathError("DeWeave", fmt.Errorf("Only accepts one boolean value"))
 			}
 
 			w, err := parseAndValidateBooleanString(filters[0])
 			if err != nil {
-				return keyError("DeWeave", err)
+				return pathError("DeWeave", err)
 			}
 
 			mf.DeWeave = w
+		case "sk":
+			if len(filters) > 1 {
+				return pathError("SessionKeys", fmt.Errorf("Only accepts one boolean value"))
+			}
+
+			k, err := parseAndValidateBooleanString(filters[0])
+			if err != nil {
+				return pathError("SessionKeys", err)
+			}
+
+			mf.SessionKeys = k
+		case "sd":
+			d, err := parseSessionData(filters)
+			if err != nil {
+				return pathError("SessionData", err)
+			}
+
+			mf.SessionData = append(mf.SessionData, d)
 		case "phe":
 			if len(filters) > 1 {
-				return keyError("PreventHTTPStatusError", fmt.Errorf("Only accepts one boolean value"))
+				return pathError("PreventHTTPStatusError", fmt.Errorf("Only accepts one boolean value"))
 			}
 
 			f, err := parseAndValidateBooleanString(filters[0])
 			if err != nil {
-				return keyError("PreventHTTPStatusError", err)
+				return pathError("PreventHTTPStatusError", err)
 			}
 
 			mf.PreventHTTPStatusError = f
@@ -218,7 +468,7 @@ func URLParse(urlpath string) (string, *MediaFilters, error) {
 
 	mf.normalizeBitrateFilter()
 
-	return masterManifestPath, mf, nil
+	return masterManifestPath, nil
 }
 
 func (mf *MediaFilters) parsePlugins(path string) bool {
@@ -306,11 +556,90 @@ func (nf *NestedFilters) parseKeys(key string, values []string) error {
 			Min: x,
 			Max: y,
 		}
+	case "ch":
+		max, err := parseMaxChannels(values)
+		if err != nil {
+			return err
+		}
+		nf.MaxChannels = max
+	case "forced":
+		if len(values) != 1 || (values[0] != ForcedDrop && values[0] != ForcedOnly) {
+			return fmt.Errorf("Forced only accepts %v or %v", ForcedDrop, ForcedOnly)
+		}
+		nf.Forced = values[0]
+	case "role":
+		roles, err := parseRoles(values)
+		if err != nil {
+			return err
+		}
+		nf.Roles = append(nf.Roles, roles...)
+	case "cc":
+		for _, v := range values {
+			id := strings.ToUpper(v)
+			if !validInstreamID(id) {
+				return fmt.Errorf("Instream ID %v is not supported", v)
+			}
+			nf.InstreamIDs = append(nf.InstreamIDs, id)
+		}
+	default:
+		if _, constrained := codecFamilies[key]; constrained {
+			c, err := parseCodecConstraint(key, values)
+			if err != nil {
+				return err
+			}
+			nf.CodecConstraints = append(nf.CodecConstraints, c)
+		}
 	}
 
 	return nil
 }
 
+// validInstreamID returns true for CEA-608 channels CC1 to CC4 and
+// CEA-708 services SERVICE1 to SERVICE63
+func validInstreamID(id string) bool {
+	if strings.HasPrefix(id, "CC") {
+		n, err := strconv.Atoi(strings.TrimPrefix(id, "CC"))
+		return err == nil && n >= 1 && n <= 4
+	}
+
+	if strings.HasPrefix(id, "SERVICE") {
+		n, err := strconv.Atoi(strings.TrimPrefix(id, "SERVICE"))
+		return err == nil && n >= 1 && n <= 63
+	}
+
+	return false
+}
+
+// parseMaxChannels validates the single value of a ch() filter, the maximum
+// number of audio channels
+func parseMaxChannels(values []string) (int, error) {
+	if len(values) != 1 {
+		return 0, fmt.Errorf("Channels only accepts one value")
+	}
+
+	max, err := strconv.Atoi(values[0])
+	if err != nil || max < 1 {
+		return 0, fmt.Errorf("Channels %v must be a positive integer", values[0])
+	}
+
+	return max, nil
+}
+
+// parseRoles validates the roles supplied to the role filter, named after the
+// DASH role scheme
+func parseRoles(values []string) ([]string, error) {
+	var roles []string
+	for _, v := range values {
+		role := strings.ToLower(v)
+		if _, valid := roleSupported[role]; !valid {
+			return nil, fmt.Errorf("Role %v is not supported", v)
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
 // normalizeBitrateFilter will finalize the nested bitrate filter by comparing it to
 // overall bitrate filter and overriding any necessary values
 func (mf *MediaFilters) normalizeBitrateFilter() {
@@ -372,6 +701,113 @@ func (t *Tags) parse(values []string) {
 	}
 }
 
+// parse sets the ladder from n:<max>, keep:<highest|lowest|even> and
+// ratio:<min ratio> values
+func (l *Ladder) parse(values []string) error {
+	for _, value := range values {
+		kv := strings.SplitN(value, ":", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("expected key:value, got %q", value)
+		}
+
+		switch key, v := kv[0], kv[1]; key {
+		case "n":
+			max, err := strconv.Atoi(v)
+			if err != nil || max < 1 {
+				return fmt.Errorf("n must be a positive integer, got %q", v)
+			}
+			l.Max = max
+		case "keep":
+			switch v {
+			case LadderKeepHighest, LadderKeepLowest, LadderKeepEven:
+				l.Keep = v
+			default:
+				return fmt.Errorf("keep must be one of highest, lowest or even, got %q", v)
+			}
+		case "ratio":
+			ratio, err := strconv.ParseFloat(v, 64)
+			if err != nil || ratio <= 1 {
+				return fmt.Errorf("ratio must be a number greater than 1, got %q", v)
+			}
+			l.MinRatio = ratio
+		default:
+			return fmt.Errorf("unsupported key %q", key)
+		}
+	}
+
+	if l.Max == 0 && l.MinRatio == 0 {
+		return fmt.Errorf("expected n or ratio to be set")
+	}
+
+	if l.Keep == "" {
+		l.Keep = LadderKeepHighest
+	}
+
+	return nil
+}
+
+// parse sets the sort from a field and an optional order, ascending by default
+func (s *Sort) parse(values []string) error {
+	if len(values) > 2 {
+		return fmt.Errorf("expected a field and an optional order, got %v values", len(values))
+	}
+
+	if values[0] != "bw" {
+		return fmt.Errorf("unsupported field %q", values[0])
+	}
+	s.Field = values[0]
+	s.Order = SortAscending
+
+	if len(values) == 2 {
+		switch values[1] {
+		case SortAscending, SortDescending:
+			s.Order = values[1]
+		default:
+			return fmt.Errorf("order must be asc or desc, got %q", values[1])
+		}
+	}
+
+	return nil
+}
+
+// parse sets the target bandwidth from a bw:<bandwidth> value
+func (f *First) parse(values []string) error {
+	kv := strings.SplitN(values[0], ":", 2)
+	if len(values) > 1 || len(kv) != 2 || kv[0] != "bw" {
+		return fmt.Errorf("expected a single bw:<bandwidth> value")
+	}
+
+	b, err := strconv.Atoi(kv[1])
+	if err != nil || b < 0 {
+		return fmt.Errorf("bandwidth must be a positive integer, got %q", kv[1])
+	}
+	f.Bandwidth = b
+
+	return nil
+}
+
+// parse sets the preferred languages from a(...) and c(...) nested values
+func (d *Defaults) parse(nestedFilters []string) error {
+	for _, nf := range nestedFilters {
+		subparts := urlParseRegexp.FindStringSubmatch(strings.TrimSuffix(nf, ","))
+		if len(subparts) != 3 || subparts[2] == "" {
+			return fmt.Errorf("expected a(<languages>) or c(<languages>), got %q", nf)
+		}
+
+		langs := strings.Split(subparts[2], ",")
+		switch subparts[1] {
+		case "a":
+			d.Audio = append(d.Audio, langs...)
+		case "c":
+			d.Captions = append(d.Captions, langs...)
+		default:
+			return fmt.Errorf("unsupported content type %q", subparts[1])
+		}
+	}
+
+	return nil
+}
+
 // SuppressAds will evaluate whether the ad tag was set
 func (mf *MediaFilters) SuppressAds() bool {
 	if mf.Tags == nil {
@@ -531,6 +531,360 @@ func TestURLParseUrl(t *testing.T) {
 			"/path/here/to/master.m3u8",
 			false,
 		},
+		{
+			"detect expression filter when passed in url",
+			`expr(codecs~"hvc" && height>1080 && !(fps==60))/path/here/to/master.m3u8`,
+			MediaFilters{
+				Expressions: []Expression{mustParseExpression(`codecs~"hvc" && height>1080 && !(fps==60)`)},
+				Protocol:    ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"invalid expression throws error",
+			"expr(height>)/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"detect ladder filter when passed in url",
+			"ladder(n:4,keep:even,ratio:1.5)/path/here/to/master.m3u8",
+			MediaFilters{
+				Ladder:   &Ladder{Max: 4, Keep: LadderKeepEven, MinRatio: 1.5},
+				Protocol: ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"ladder filter keeps the highest renditions by default",
+			"ladder(n:2)/path/here/to/master.mpd",
+			MediaFilters{
+				Ladder:   &Ladder{Max: 2, Keep: LadderKeepHighest},
+				Protocol: ProtocolDASH,
+			},
+			"/path/here/to/master.mpd",
+			false,
+		},
+		{
+			"ladder filter without size nor ratio throws error",
+			"ladder(keep:lowest)/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"ladder filter with a ratio lower than 1 throws error",
+			"ladder(ratio:0.5)/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"detect sort and first filters when passed in url",
+			"sort(bw,desc)/first(bw:2000000)/path/here/to/master.m3u8",
+			MediaFilters{
+				Sort:     &Sort{Field: "bw", Order: SortDescending},
+				First:    &First{Bandwidth: 2000000},
+				Protocol: ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"sort filter is ascending by default",
+			"sort(bw)/path/here/to/master.mpd",
+			MediaFilters{
+				Sort:     &Sort{Field: "bw", Order: SortAscending},
+				Protocol: ProtocolDASH,
+			},
+			"/path/here/to/master.mpd",
+			false,
+		},
+		{
+			"sort filter with an unsupported field throws error",
+			"sort(fps,asc)/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"first filter without a bandwidth throws error",
+			"first(2000000)/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"detect video range filter when passed in url",
+			"vr(pq,hlg)/path/here/to/master.m3u8",
+			MediaFilters{
+				VideoRanges: []string{"pq", "hlg"},
+				Protocol:    ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"unsupported video range throws error",
+			"vr(hdr)/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"detect session keys and session data when passed in url",
+			"sk(true)/sd(com.example.content-id,1234)/sd(com.example.title,Discovery,en)/path/here/to/master.m3u8",
+			MediaFilters{
+				SessionKeys: true,
+				SessionData: []SessionData{
+					{ID: "com.example.content-id", Value: "1234"},
+					{ID: "com.example.title", Value: "Discovery", Language: "en"},
+				},
+				Protocol: ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"session keys filter with a non boolean value throws error",
+			"sk(yes)/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"session data filter without a value throws error",
+			"sd(com.example.content-id)/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"session data filter closing its quoted value throws error",
+			"sd(com.example.content-id,1234\" URI=\"https:)/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"detect drm systems when passed in url",
+			"drm(widevine,PlayReady)/path/here/to/master.mpd",
+			MediaFilters{
+				DRMSystems: []string{"widevine", "playready"},
+				Protocol:   ProtocolDASH,
+			},
+			"/path/here/to/master.mpd",
+			false,
+		},
+		{
+			"unsupported drm system throws error",
+			"drm(primetime)/path/here/to/master.mpd",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"detect maximum audio channels when passed in url",
+			"ch(6)/path/here/to/master.m3u8",
+			MediaFilters{
+				Audios:   NestedFilters{MaxChannels: 6},
+				Protocol: ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"detect maximum audio channels nested in the audio filter",
+			"a(ec-3,ch(2))/path/here/to/master.m3u8",
+			MediaFilters{
+				Audios:   NestedFilters{Codecs: []string{"ec-3"}, MaxChannels: 2},
+				Protocol: ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"invalid maximum audio channels throws error",
+			"ch(0)/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"detect codec constraints nested in the video filter",
+			"v(avc(level<=4.1),hevc(profile=main))/path/here/to/master.m3u8",
+			MediaFilters{
+				Videos: NestedFilters{
+					CodecConstraints: []CodecConstraint{
+						{Family: CodecFamilyAVC, Conditions: []CodecCondition{{Attribute: "level", Operator: "<=", Value: "4.1"}}},
+						{Family: CodecFamilyHEVC, Conditions: []CodecCondition{{Attribute: "profile", Operator: "==", Value: "main"}}},
+					},
+				},
+				Protocol: ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"detect codec constraints alongside removed codecs",
+			"v(dvh,hvc(profile=main,tier=main))/path/here/to/master.m3u8",
+			MediaFilters{
+				Videos: NestedFilters{
+					Codecs: []string{"dvh"},
+					CodecConstraints: []CodecConstraint{
+						{Family: CodecFamilyHEVC, Conditions: []CodecCondition{
+							{Attribute: "profile", Operator: "==", Value: "main"},
+							{Attribute: "tier", Operator: "==", Value: "main"},
+						}},
+					},
+				},
+				Protocol: ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"unsupported codec constraint throws error",
+			"v(avc(profile=main10))/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"detect frame rate range with only an upper bound when passed in url",
+			"fps(max:30)/path/here/to/master.m3u8",
+			MediaFilters{
+				FrameRateRange: &FrameRateRange{Max: 30},
+				Protocol:       ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"detect frame rate range with a fraction lower bound and no upper bound",
+			"fps(min:50000:1001)/path/here/to/master.mpd",
+			MediaFilters{
+				FrameRateRange: &FrameRateRange{Min: 50000.0 / 1001},
+				Protocol:       ProtocolDASH,
+			},
+			"/path/here/to/master.mpd",
+			false,
+		},
+		{
+			"detect frame rate range with both bounds when passed in url",
+			"fps(min:24,max:30)/path/here/to/master.m3u8",
+			MediaFilters{
+				FrameRateRange: &FrameRateRange{Min: 24, Max: 30},
+				Protocol:       ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"plain frame rates starting at 0 are a list and not a range",
+			"fps(0,30)/path/here/to/master.m3u8",
+			MediaFilters{
+				FrameRateRange: nil,
+				FrameRate:      []string{"0", "30"},
+				Protocol:       ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"mixing frame rate range bounds and plain frame rates throws error",
+			"fps(min:24,60)/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"frame rate range with the lower bound above the upper bound throws error",
+			"fps(min:60,max:30)/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"detect underscore language tags when passed in url",
+			"l(en_US)/path/here/to/master.m3u8",
+			MediaFilters{
+				Audios:   NestedFilters{Language: []string{"en_US"}},
+				Captions: NestedFilters{Language: []string{"en_US"}},
+				Protocol: ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"invalid frame rate throws error",
+			"fps(fast)/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"detect default audio and caption languages when passed in url",
+			"def(a(es,en),c(en))/path/here/to/master.m3u8",
+			MediaFilters{
+				Defaults: &Defaults{Audio: []string{"es", "en"}, Captions: []string{"en"}},
+				Protocol: ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"unsupported default content type throws error",
+			"def(v(en))/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"detect forced subtitles and instream ids nested in the caption filter",
+			"c(forced(drop),cc(cc3,SERVICE2))/path/here/to/master.m3u8",
+			MediaFilters{
+				Captions: NestedFilters{Forced: ForcedDrop, InstreamIDs: []string{"CC3", "SERVICE2"}},
+				Protocol: ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"unsupported instream id throws error",
+			"c(cc(CC5))/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"unsupported forced value throws error",
+			"c(forced(yes))/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
+		{
+			"detect roles applied to audio and captions",
+			"role(Description,commentary)/a(role(dub))/path/here/to/master.m3u8",
+			MediaFilters{
+				Audios:   NestedFilters{Roles: []string{"description", "commentary", "dub"}},
+				Captions: NestedFilters{Roles: []string{"description", "commentary"}},
+				Protocol: ProtocolHLS,
+			},
+			"/path/here/to/master.m3u8",
+			false,
+		},
+		{
+			"unsupported role throws error",
+			"role(director)/path/here/to/master.m3u8",
+			MediaFilters{},
+			"",
+			true,
+		},
 		{
 			"detect protocol hls for urls with .m3u8 extension",
 			"/path/here/with/master.m3u8",
@@ -740,3 +1094,186 @@ func TestParsers_SuppressTags(t *testing.T) {
 		})
 	}
 }
+
+func TestParsers_FilterParse(t *testing.T) {
+	tests := []struct {
+		name            string
+		filterPath      string
+		filtersJSON     string
+		protocol        Protocol
+		expectedFilters MediaFilters
+		expectedErr     bool
+	}{
+		{
+			name:       "filter path without manifest is parsed for the given protocol",
+			filterPath: "/v(hevc)/b(0,3000)/",
+			protocol:   ProtocolDASH,
+			expectedFilters: MediaFilters{
+				Videos: NestedFilters{
+					Codecs:  []string{"hevc"},
+					Bitrate: &Bitrate{Min: 0, Max: 3000},
+				},
+				Audios: NestedFilters{
+					Bitrate: &Bitrate{Min: 0, Max: 3000},
+				},
+				Protocol: ProtocolDASH,
+			},
+		},
+		{
+			name:        "invalid filter path returns an error",
+			filterPath:  "/b(3000,0)/",
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters are normalized",
+			filtersJSON: `{"Bitrate":{"Max":3000},"Audios":{"Language":["en"]}}`,
+			protocol:    ProtocolHLS,
+			expectedFilters: MediaFilters{
+				Videos: NestedFilters{
+					Bitrate: &Bitrate{Min: 0, Max: 3000},
+				},
+				Audios: NestedFilters{
+					Bitrate:  &Bitrate{Min: 0, Max: 3000},
+					Language: []string{"en"},
+				},
+				Protocol: ProtocolHLS,
+			},
+		},
+		{
+			name:        "json filters with an unsupported codec return an error",
+			filtersJSON: `{"Videos":{"Codecs":["h264"]}}`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters with an unsupported content type return an error",
+			filtersJSON: `{"ContentTypes":["subtitles"]}`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters with an invalid language return an error",
+			filtersJSON: `{"Audios":{"Language":["en\",\"fr"]}}`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters with an invalid default language return an error",
+			filtersJSON: `{"Defaults":{"Audio":[""]}}`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters with an invalid frame rate return an error",
+			filtersJSON: `{"FrameRate":["fast"]}`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters with an inverted frame rate range return an error",
+			filtersJSON: `{"FrameRateRange":{"Min":60,"Max":30}}`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters with a negative channel count return an error",
+			filtersJSON: `{"Audios":{"MaxChannels":-2}}`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters with an unsupported drm system return an error",
+			filtersJSON: `{"DRMSystems":["primetime"]}`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters with an inverted bitrate range return an error",
+			filtersJSON: `{"Videos":{"Bitrate":{"Min":3000,"Max":1000}}}`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters with an unsupported role return an error",
+			filtersJSON: `{"Captions":{"Roles":["director"]}}`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters with an unsupported instream id return an error",
+			filtersJSON: `{"Captions":{"InstreamIDs":["CC5"]}}`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters with an unsupported codec profile return an error",
+			filtersJSON: `{"Videos":{"CodecConstraints":[{"Family":"avc","Conditions":[{"Attribute":"profile","Operator":"==","Value":"main10"}]}]}}`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters with a ladder missing its size and ratio return an error",
+			filtersJSON: `{"Ladder":{"Keep":"even"}}`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters with invalid session data return an error",
+			filtersJSON: `{"SessionData":[{"id":"title","value":"1234"}]}`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+		{
+			name:        "json filters with underscore language tags are accepted",
+			filtersJSON: `{"Audios":{"Language":["en_US"]}}`,
+			protocol:    ProtocolHLS,
+			expectedFilters: MediaFilters{
+				Audios:   NestedFilters{Language: []string{"en_US"}},
+				Protocol: ProtocolHLS,
+			},
+		},
+		{
+			name:        "valid json filters with drm systems are lowercased",
+			filtersJSON: `{"DRMSystems":["Widevine"],"Videos":{"Codecs":["hev1.2","avc"]},"Ladder":{"Max":2}}`,
+			protocol:    ProtocolDASH,
+			expectedFilters: MediaFilters{
+				Videos:     NestedFilters{Codecs: []string{"hev1.2", "avc"}},
+				DRMSystems: []string{"widevine"},
+				Ladder:     &Ladder{Max: 2},
+				Protocol:   ProtocolDASH,
+			},
+		},
+		{
+			name:        "malformed json filters returns an error",
+			filtersJSON: `{"Bitrate":`,
+			protocol:    ProtocolHLS,
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			var output *MediaFilters
+			var err error
+			if test.filtersJSON != "" {
+				output, err = JSONParse([]byte(test.filtersJSON), test.protocol)
+			} else {
+				output, err = FilterParse(test.filterPath, test.protocol)
+			}
+
+			if !test.expectedErr && err != nil {
+				t.Errorf("Did not expect an error returned, got: %v", err)
+				return
+			} else if test.expectedErr && err == nil {
+				t.Errorf("Expected an error returned, got nil")
+				return
+			}
+
+			if !cmp.Equal(*output, test.expectedFilters) {
+				t.Errorf("wrong struct generated.\nwant %v\ngot %v\n diff: %v", test.expectedFilters, *output, cmp.Diff(test.expectedFilters, *output))
+			}
+		})
+	}
+}
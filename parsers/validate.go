@@ -0,0 +1,212 @@
+package parsers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// languageTagRegexp matches BCP-47 language tags, along with the en_US form
+// normalized when matching languages
+var languageTagRegexp = regexp.MustCompile(`^[A-Za-z0-9]+([-_][A-Za-z0-9]+)*$`)
+
+// validate runs the checks applied when parsing a filter path on filters
+// decoded from json, returning the first invalid value. Keep and Order left
+// empty fall back to their defaults, highest and ascending
+func (mf *MediaFilters) validate() error {
+	if err := mf.Videos.validate(); err != nil {
+		return fmt.Errorf("Video: %w", err)
+	}
+
+	if err := mf.Audios.validate(); err != nil {
+		return fmt.Errorf("Audio: %w", err)
+	}
+
+	if err := mf.Captions.validate(); err != nil {
+		return fmt.Errorf("Captions: %w", err)
+	}
+
+	for _, contentType := range mf.ContentTypes {
+		if _, valid := contentSupported[contentType]; !valid {
+			return fmt.Errorf("Content Type: Content Type %v is not supported", contentType)
+		}
+	}
+
+	if mf.Bitrate != nil {
+		if err := mf.Bitrate.validate(); err != nil {
+			return fmt.Errorf("Bitrate: %w", err)
+		}
+	}
+
+	if mf.Trim != nil && !validatePositiveRange(mf.Trim.Start, mf.Trim.End, int(time.Now().Unix())) {
+		return fmt.Errorf("Trim: invalid range for provided values: ( %v, %v )", mf.Trim.Start, mf.Trim.End)
+	}
+
+	for _, framerate := range mf.FrameRate {
+		if _, valid := ParseFrameRate(framerate); !valid {
+			return fmt.Errorf("Frame Rate: Frame rate %v is not a number or a ratio", framerate)
+		}
+	}
+
+	if r := mf.FrameRateRange; r != nil && (r.Min < 0 || r.Max < 0 || (r.Max != 0 && r.Min > r.Max)) {
+		return fmt.Errorf("Frame Rate: invalid range for provided values: ( %v, %v )", r.Min, r.Max)
+	}
+
+	for _, vr := range mf.VideoRanges {
+		if _, valid := videoRangeSupported[vr]; !valid {
+			return fmt.Errorf("Video Range: Video range %v is not supported", vr)
+		}
+	}
+
+	for i, drm := range mf.DRMSystems {
+		system := strings.ToLower(drm)
+		if _, valid := drmSupported[system]; !valid {
+			return fmt.Errorf("DRM: DRM system %v is not supported", drm)
+		}
+		mf.DRMSystems[i] = system
+	}
+
+	if mf.Ladder != nil {
+		if err := mf.Ladder.validate(); err != nil {
+			return fmt.Errorf("Ladder: %w", err)
+		}
+	}
+
+	if mf.Sort != nil {
+		if err := mf.Sort.validate(); err != nil {
+			return fmt.Errorf("Sort: %w", err)
+		}
+	}
+
+	if mf.First != nil && mf.First.Bandwidth < 0 {
+		return fmt.Errorf("First: bandwidth must be a positive integer, got %v", mf.First.Bandwidth)
+	}
+
+	if mf.Defaults != nil {
+		for _, langs := range [][]string{mf.Defaults.Audio, mf.Defaults.Captions} {
+			if err := validateLanguages(langs); err != nil {
+				return fmt.Errorf("Defaults: %w", err)
+			}
+		}
+	}
+
+	for _, d := range mf.SessionData {
+		if err := d.Validate(); err != nil {
+			return fmt.Errorf("SessionData: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (nf NestedFilters) validate() error {
+	for _, codec := range nf.Codecs {
+		if _, valid := codecSupported[codec]; !valid && !isHDR10Codec(codec) {
+			return fmt.Errorf("Codec %v is not supported", codec)
+		}
+	}
+
+	if err := validateLanguages(nf.Language); err != nil {
+		return err
+	}
+
+	if nf.Bitrate != nil {
+		if err := nf.Bitrate.validate(); err != nil {
+			return err
+		}
+	}
+
+	if nf.MaxChannels < 0 {
+		return fmt.Errorf("Channels %v must be a positive integer", nf.MaxChannels)
+	}
+
+	for _, c := range nf.CodecConstraints {
+		if c.Family != CodecFamilyAVC && c.Family != CodecFamilyHEVC {
+			return fmt.Errorf("Codec %v does not support constraints", c.Family)
+		}
+		for _, cond := range c.Conditions {
+			if _, err := parseCodecCondition(c.Family, cond.Attribute+cond.Operator+cond.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	if nf.Forced != "" && nf.Forced != ForcedDrop && nf.Forced != ForcedOnly {
+		return fmt.Errorf("Forced only accepts %v or %v", ForcedDrop, ForcedOnly)
+	}
+
+	for _, id := range nf.InstreamIDs {
+		if !validInstreamID(id) {
+			return fmt.Errorf("Instream ID %v is not supported", id)
+		}
+	}
+
+	for _, role := range nf.Roles {
+		if _, valid := roleSupported[role]; !valid {
+			return fmt.Errorf("Role %v is not supported", role)
+		}
+	}
+
+	return nil
+}
+
+// isHDR10Codec returns true for the codecs the hdr10 codec filter expands to
+func isHDR10Codec(codec string) bool {
+	return codec == "hev1.2" || codec == "hvc1.2"
+}
+
+func (b Bitrate) validate() error {
+	if !validatePositiveRange(b.Min, b.Max, math.MaxInt32) {
+		return fmt.Errorf("invalid range for provided values: ( %v, %v )", b.Min, b.Max)
+	}
+
+	return nil
+}
+
+func (l Ladder) validate() error {
+	if l.Max < 0 {
+		return fmt.Errorf("n must be a positive integer, got %v", l.Max)
+	}
+
+	switch l.Keep {
+	case "", LadderKeepHighest, LadderKeepLowest, LadderKeepEven:
+	default:
+		return fmt.Errorf("keep must be one of highest, lowest or even, got %q", l.Keep)
+	}
+
+	if l.MinRatio != 0 && l.MinRatio <= 1 {
+		return fmt.Errorf("ratio must be a number greater than 1, got %v", l.MinRatio)
+	}
+
+	if l.Max == 0 && l.MinRatio == 0 {
+		return fmt.Errorf("expected n or ratio to be set")
+	}
+
+	return nil
+}
+
+func (s Sort) validate() error {
+	if s.Field != "bw" {
+		return fmt.Errorf("unsupported field %q", s.Field)
+	}
+
+	if s.Order != "" && s.Order != SortAscending && s.Order != SortDescending {
+		return fmt.Errorf("order must be asc or desc, got %q", s.Order)
+	}
+
+	return nil
+}
+
+// validateLanguages returns an error if any of the languages is not a
+// language tag, such as en, pt-BR or en_US
+func validateLanguages(languages []string) error {
+	for _, lang := range languages {
+		if !languageTagRegexp.MatchString(lang) {
+			return fmt.Errorf("Language %q is not a language tag", lang)
+		}
+	}
+
+	return nil
+}